@@ -0,0 +1,91 @@
+package googlesearch
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Ad is a text ad shown above or below the organic results.
+type Ad struct {
+	// Block is "top" or "bottom"; Position is 1-based within the block.
+	Block       string
+	Position    int
+	Title       string
+	URL         string
+	DisplayURL  string
+	Description string
+}
+
+func extractAds(doc *goquery.Document) []Ad {
+	var ads []Ad
+	for _, block := range []struct{ name, selector string }{
+		{"top", "div#tads div[data-text-ad]"},
+		{"bottom", "div#tadsb div[data-text-ad], div#bottomads div[data-text-ad]"},
+	} {
+		doc.Find(block.selector).Each(func(i int, s *goquery.Selection) {
+			link := s.Find("a[href]").First()
+			target, ok := link.Attr("data-pcu")
+			if !ok {
+				href, _ := link.Attr("href")
+				target = resolveGoogleURL(doc.Url, href)
+			}
+			ad := Ad{
+				Block:       block.name,
+				Position:    i + 1,
+				Title:       normalizeSpace(s.Find("div[role='heading']").First().Text()),
+				URL:         strings.SplitN(target, ",", 2)[0],
+				DisplayURL:  normalizeSpace(s.Find("span.x2VHCd, span[role='text']").First().Text()),
+				Description: normalizeSpace(s.Find("div.MUxGbd, div.Va3FIb, div.yDYNvb").First().Text()),
+			}
+			if ad.Title != "" || ad.URL != "" {
+				ads = append(ads, ad)
+			}
+		})
+	}
+	return ads
+}
+
+// ResultStats is Google's "About N results (S seconds)" line.
+type ResultStats struct {
+	TotalResults int64
+	SearchTime   time.Duration
+}
+
+var (
+	statsCountPattern = regexp.MustCompile(`\d[\d.,\s\x{00a0}\x{202f}']*`)
+	statsTimePattern  = regexp.MustCompile(`\(\D*(\d+[.,]\d+)`)
+)
+
+// extractResultStats parses the stats line in any interface language by
+// taking its first number as the result count and the decimal number in
+// parentheses as the search time.
+func extractResultStats(doc *goquery.Document) *ResultStats {
+	text := normalizeSpace(doc.Find("div#result-stats").First().Text())
+	if text == "" {
+		return nil
+	}
+	stats := &ResultStats{}
+	count := text
+	if i := strings.Index(text, "("); i >= 0 {
+		count = text[:i]
+	}
+	if m := statsCountPattern.FindString(count); m != "" {
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, m)
+		stats.TotalResults, _ = strconv.ParseInt(digits, 10, 64)
+	}
+	if m := statsTimePattern.FindStringSubmatch(text); m != nil {
+		if seconds, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64); err == nil {
+			stats.SearchTime = time.Duration(seconds * float64(time.Second))
+		}
+	}
+	return stats
+}
@@ -0,0 +1,38 @@
+package googlesearch
+
+import "sort"
+
+// DomainSummary aggregates the results of one registrable domain.
+type DomainSummary struct {
+	Domain string
+	Count  int
+	// BestPosition is the 1-based position of the domain's first result.
+	BestPosition int
+	URLs         []string
+}
+
+// AggregateByDomain groups results by registrable domain (eTLD+1, so
+// www.example.co.uk and blog.example.co.uk both count for example.co.uk).
+// Summaries are ordered by best position.
+func AggregateByDomain(results []SearchResult) []DomainSummary {
+	index := make(map[string]int)
+	var summaries []DomainSummary
+	for i, result := range results {
+		domain := registrableDomain(result.URL)
+		if domain == "" {
+			continue
+		}
+		j, ok := index[domain]
+		if !ok {
+			j = len(summaries)
+			index[domain] = j
+			summaries = append(summaries, DomainSummary{Domain: domain, BestPosition: i + 1})
+		}
+		summaries[j].Count++
+		summaries[j].URLs = append(summaries[j].URLs, result.URL)
+	}
+	sort.SliceStable(summaries, func(a, b int) bool {
+		return summaries[a].BestPosition < summaries[b].BestPosition
+	})
+	return summaries
+}
@@ -0,0 +1,93 @@
+package googlesearch
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// detectAMP fills AMPURL and CanonicalURL when the result links to an AMP
+// page, either through the AMP cache, Google's AMP viewer or a publisher
+// hosted AMP variant.
+func detectAMP(result *SearchResult, link *goquery.Selection) {
+	if amp, ok := link.Attr("data-amp"); ok && amp != "" {
+		result.AMPURL = amp
+		result.CanonicalURL = link.AttrOr("data-amp-cur", "")
+		if result.CanonicalURL == "" {
+			result.CanonicalURL, _ = ampCanonicalURL(amp)
+		}
+		return
+	}
+
+	if canonical, ok := ampCanonicalURL(result.URL); ok {
+		result.AMPURL = result.URL
+		result.CanonicalURL = canonical
+	}
+}
+
+// ampCanonicalURL derives the publisher URL behind an AMP URL. The second
+// return value reports whether rawURL looked like an AMP URL at all.
+func ampCanonicalURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case strings.HasSuffix(host, ".cdn.ampproject.org"):
+		return cachePathToURL(u.Path, u.RawQuery, "/c/", "/v/", "/i/")
+	case (host == "www.google.com" || strings.HasPrefix(host, "google.")) && strings.HasPrefix(u.Path, "/amp/"):
+		return cachePathToURL(u.Path, u.RawQuery, "/amp/")
+	}
+
+	canonical := *u
+	isAMP := false
+	if strings.HasPrefix(host, "amp.") {
+		canonical.Host = strings.TrimPrefix(u.Host, "amp.")
+		isAMP = true
+	}
+	if path := strings.TrimSuffix(u.Path, "/"); strings.HasSuffix(path, "/amp") {
+		canonical.Path = strings.TrimSuffix(path, "/amp")
+		isAMP = true
+	} else if strings.Contains(u.Path, "/amp/") {
+		canonical.Path = strings.Replace(u.Path, "/amp/", "/", 1)
+		isAMP = true
+	}
+	if q := u.Query(); q.Has("amp") || q.Get("outputType") == "amp" {
+		q.Del("amp")
+		q.Del("outputType")
+		canonical.RawQuery = q.Encode()
+		isAMP = true
+	}
+	if !isAMP {
+		return "", false
+	}
+	return canonical.String(), true
+}
+
+// cachePathToURL rebuilds the origin URL from AMP cache style paths like
+// /c/s/example.com/article, where the optional "s/" segment marks https.
+func cachePathToURL(path, rawQuery string, prefixes ...string) (string, bool) {
+	for _, prefix := range prefixes {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(path, prefix)
+		scheme := "http://"
+		if strings.HasPrefix(rest, "s/") {
+			scheme = "https://"
+			rest = strings.TrimPrefix(rest, "s/")
+		}
+		if rest == "" {
+			return "", false
+		}
+		canonical := scheme + rest
+		if rawQuery != "" {
+			canonical += "?" + rawQuery
+		}
+		return canonical, true
+	}
+	return "", false
+}
@@ -0,0 +1,422 @@
+package googlesearch
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SERPMetadata holds the answer boxes and other non-organic data found on
+// the result pages of a search. Fields are nil when Google did not show the
+// corresponding box.
+type SERPMetadata struct {
+	Features SERPFeatures
+
+	// PagesFetched is the number of results pages requested. Exhausted
+	// reports that the search stopped because Google returned no new
+	// results, or fewer than MinNewResultsPerPage, rather than because
+	// enough results were collected.
+	PagesFetched int
+	Exhausted    bool
+	// BytesDownloaded is the compressed size of all responses the search
+	// received. BandwidthExceeded reports that BandwidthQuota stopped it.
+	BytesDownloaded   int64
+	BandwidthExceeded bool
+
+	Currency *CurrencyAnswer
+	Unit     *UnitAnswer
+	Weather  *WeatherAnswer
+
+	Definitions []DictionaryEntry
+	Sports      []SportsGame
+	Flights     *FlightsBox
+	Events      []Event
+	Jobs        []Job
+	Recipes     []RecipeResult
+	SocialPosts []SocialPost
+	Videos      []Video
+	Images      []InlineImage
+	LocalPack   []LocalResult
+
+	RelatedSearches []string
+	PeopleAlsoAsk   []string
+
+	// Omitted is set when Google folded results similar to those shown.
+	Omitted *OmittedResults
+
+	// Domains is the per-domain summary requested with DomainSummary.
+	Domains []DomainSummary
+
+	Ads             []Ad
+	ResultStats     *ResultStats
+	FeaturedSnippet *FeaturedSnippet
+
+	// CachedAt is when results served from Cache were fetched; it is zero
+	// for fresh results. Each cached SearchResult carries it as well.
+	CachedAt time.Time
+}
+
+// merge copies boxes found on a later page that were missing so far. Answer
+// boxes normally only appear on the first page.
+func (m *SERPMetadata) merge(other SERPMetadata) {
+	m.Features.merge(other.Features)
+	if m.Currency == nil {
+		m.Currency = other.Currency
+	}
+	if m.Unit == nil {
+		m.Unit = other.Unit
+	}
+	if m.Weather == nil {
+		m.Weather = other.Weather
+	}
+	if m.Definitions == nil {
+		m.Definitions = other.Definitions
+	}
+	if m.Sports == nil {
+		m.Sports = other.Sports
+	}
+	if m.Flights == nil {
+		m.Flights = other.Flights
+	}
+	if m.Events == nil {
+		m.Events = other.Events
+	}
+	if m.Jobs == nil {
+		m.Jobs = other.Jobs
+	}
+	if m.Recipes == nil {
+		m.Recipes = other.Recipes
+	}
+	if m.SocialPosts == nil {
+		m.SocialPosts = other.SocialPosts
+	}
+	if m.Videos == nil {
+		m.Videos = other.Videos
+	}
+	if m.Images == nil {
+		m.Images = other.Images
+	}
+	if m.LocalPack == nil {
+		m.LocalPack = other.LocalPack
+	}
+	if m.RelatedSearches == nil {
+		m.RelatedSearches = other.RelatedSearches
+	}
+	if m.PeopleAlsoAsk == nil {
+		m.PeopleAlsoAsk = other.PeopleAlsoAsk
+	}
+	if m.Omitted == nil {
+		m.Omitted = other.Omitted
+	}
+	if m.Ads == nil {
+		m.Ads = other.Ads
+	}
+	if m.ResultStats == nil {
+		m.ResultStats = other.ResultStats
+	}
+	if m.FeaturedSnippet == nil {
+		m.FeaturedSnippet = other.FeaturedSnippet
+	}
+}
+
+// Age returns how old the results are: zero for fresh results and the
+// time since CachedAt for cached ones.
+func (m *SERPMetadata) Age() time.Duration {
+	if m == nil || m.CachedAt.IsZero() {
+		return 0
+	}
+	return time.Since(m.CachedAt)
+}
+
+func extractMetadata(doc *goquery.Document, opts SearchOptions) SERPMetadata {
+	m := SERPMetadata{Features: extractFeatures(doc)}
+	if opts.FeatureSummaryOnly {
+		return m
+	}
+	m.Currency, m.Unit = extractConversion(doc)
+	m.Weather = extractWeather(doc)
+	m.Definitions = extractDefinitions(doc)
+	m.Sports = extractSports(doc)
+	m.Flights = extractFlights(doc)
+	m.Events = extractEvents(doc)
+	m.Jobs = extractJobs(doc)
+	m.Recipes = extractRecipes(doc)
+	m.SocialPosts = extractSocialPosts(doc)
+	m.Videos = extractVideos(doc)
+	m.Images = extractImagePack(doc)
+	m.LocalPack = extractLocalPack(doc)
+	m.RelatedSearches = extractRelatedSearches(doc)
+	m.PeopleAlsoAsk = extractPeopleAlsoAsk(doc)
+	m.Omitted = extractOmittedResults(doc)
+	m.Ads = extractAds(doc)
+	m.ResultStats = extractResultStats(doc)
+	m.FeaturedSnippet = extractFeaturedSnippet(doc)
+	return m
+}
+
+type CurrencyAnswer struct {
+	FromAmount   float64
+	FromCurrency string
+	ToAmount     float64
+	ToCurrency   string
+	Text         string
+}
+
+type UnitAnswer struct {
+	FromValue float64
+	FromUnit  string
+	ToValue   float64
+	ToUnit    string
+	Text      string
+}
+
+var conversionPattern = regexp.MustCompile(`^\s*([\d.,\s]+?)\s+(.+?)\s*(?:=|equals)\s*([\d.,\s]+?)\s+(.+?)\s*$`)
+
+var currencyNames = []string{
+	"dollar", "euro", "pound", "yen", "yuan", "renminbi", "franc", "krona",
+	"krone", "rupee", "ruble", "rouble", "peso", "real", "won", "lira",
+	"zloty", "forint", "koruna", "rand", "dirham", "riyal", "shekel",
+	"baht", "ringgit", "rupiah", "dong", "hryvnia", "bitcoin",
+}
+
+func extractConversion(doc *goquery.Document) (*CurrencyAnswer, *UnitAnswer) {
+	if currency := extractCurrencyWidget(doc); currency != nil {
+		return currency, nil
+	}
+	if unit := extractUnitWidget(doc); unit != nil {
+		return nil, unit
+	}
+
+	var currency *CurrencyAnswer
+	var unit *UnitAnswer
+	doc.Find("div.BNeawe, div.ezO2md").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		text := normalizeSpace(s.Text())
+		m := conversionPattern.FindStringSubmatch(text)
+		if m == nil {
+			return true
+		}
+		from, err1 := parseAmount(m[1])
+		to, err2 := parseAmount(m[3])
+		if err1 != nil || err2 != nil {
+			return true
+		}
+
+		if isCurrency(m[2]) && isCurrency(m[4]) {
+			currency = &CurrencyAnswer{FromAmount: from, FromCurrency: m[2], ToAmount: to, ToCurrency: m[4], Text: text}
+		} else {
+			unit = &UnitAnswer{FromValue: from, FromUnit: m[2], ToValue: to, ToUnit: m[4], Text: text}
+		}
+		return false
+	})
+	return currency, unit
+}
+
+func extractCurrencyWidget(doc *goquery.Document) *CurrencyAnswer {
+	from := doc.Find("span.vLqKYe").First()
+	to := doc.Find("span.DFlfde.SwHCTb").First()
+	if from.Length() == 0 || to.Length() == 0 {
+		return nil
+	}
+
+	fromAmount, err := parseAmount(from.AttrOr("data-value", ""))
+	if err != nil {
+		return nil
+	}
+	toAmount, err := parseAmount(to.AttrOr("data-value", to.Text()))
+	if err != nil {
+		return nil
+	}
+
+	toCurrency := doc.Find("span.MWvIVe").First()
+	answer := &CurrencyAnswer{
+		FromAmount:   fromAmount,
+		FromCurrency: from.AttrOr("data-name", ""),
+		ToAmount:     toAmount,
+		ToCurrency:   toCurrency.AttrOr("data-name", normalizeSpace(toCurrency.Text())),
+	}
+	answer.Text = normalizeSpace(from.Text() + " " + to.Text() + " " + toCurrency.Text())
+	return answer
+}
+
+func extractUnitWidget(doc *goquery.Document) *UnitAnswer {
+	inputs := doc.Find("div.rpnBye input")
+	units := doc.Find("div.rpnBye select")
+	if inputs.Length() < 2 || units.Length() < 2 {
+		return nil
+	}
+
+	fromValue, err := parseAmount(inputs.Eq(0).AttrOr("value", ""))
+	if err != nil {
+		return nil
+	}
+	toValue, err := parseAmount(inputs.Eq(1).AttrOr("value", ""))
+	if err != nil {
+		return nil
+	}
+
+	answer := &UnitAnswer{
+		FromValue: fromValue,
+		FromUnit:  normalizeSpace(units.Eq(0).Find("option[selected]").Text()),
+		ToValue:   toValue,
+		ToUnit:    normalizeSpace(units.Eq(1).Find("option[selected]").Text()),
+	}
+	answer.Text = strings.TrimSpace(strconv.FormatFloat(fromValue, 'f', -1, 64) + " " + answer.FromUnit + " = " +
+		strconv.FormatFloat(toValue, 'f', -1, 64) + " " + answer.ToUnit)
+	return answer
+}
+
+func isCurrency(name string) bool {
+	if len(name) == 3 && strings.ToUpper(name) == name {
+		return true
+	}
+	lower := strings.ToLower(name)
+	for _, currency := range currencyNames {
+		if strings.Contains(lower, currency) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAmount parses numbers rendered with either "," or "." as the
+// thousands separator.
+func parseAmount(s string) (float64, error) {
+	s = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\u00a0' || r == '\u202f' {
+			return -1
+		}
+		return r
+	}, s)
+
+	lastComma := strings.LastIndex(s, ",")
+	lastDot := strings.LastIndex(s, ".")
+	switch {
+	case lastComma > lastDot && lastDot != -1:
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.Replace(s, ",", ".", 1)
+	case lastComma > lastDot:
+		if len(s)-lastComma-1 == 3 {
+			s = strings.ReplaceAll(s, ",", "")
+		} else {
+			s = strings.Replace(s, ",", ".", 1)
+		}
+	default:
+		s = strings.ReplaceAll(s, ",", "")
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(cleanText(s)), " ")
+}
+
+type WeatherAnswer struct {
+	Location      string
+	Time          string
+	Temperature   string
+	Unit          string
+	Condition     string
+	Precipitation string
+	Humidity      string
+	Wind          string
+	Forecast      []WeatherForecastDay
+}
+
+type WeatherForecastDay struct {
+	Day       string
+	Condition string
+	High      string
+	Low       string
+}
+
+func extractWeather(doc *goquery.Document) *WeatherAnswer {
+	box := doc.Find("div#wob_wc").First()
+	if box.Length() == 0 {
+		return nil
+	}
+
+	answer := &WeatherAnswer{
+		Location:      normalizeSpace(box.Find("#wob_loc").First().Text()),
+		Time:          normalizeSpace(box.Find("#wob_dts").First().Text()),
+		Temperature:   normalizeSpace(box.Find("#wob_tm").First().Text()),
+		Unit:          normalizeSpace(box.Find("div.wob-unit span.wob_t").First().Text()),
+		Condition:     normalizeSpace(box.Find("#wob_dc").First().Text()),
+		Precipitation: normalizeSpace(box.Find("#wob_pp").First().Text()),
+		Humidity:      normalizeSpace(box.Find("#wob_hm").First().Text()),
+		Wind:          normalizeSpace(box.Find("#wob_ws").First().Text()),
+	}
+
+	box.Find("div.wob_df").Each(func(i int, s *goquery.Selection) {
+		day := s.Find("div.Z1VzSb").First()
+		answer.Forecast = append(answer.Forecast, WeatherForecastDay{
+			Day:       day.AttrOr("aria-label", normalizeSpace(day.Text())),
+			Condition: s.Find("img").First().AttrOr("alt", ""),
+			High:      normalizeSpace(s.Find("div.gNCp2e span.wob_t").First().Text()),
+			Low:       normalizeSpace(s.Find("div.QrNVmd span.wob_t").First().Text()),
+		})
+	})
+
+	if answer.Temperature == "" && answer.Location == "" {
+		return nil
+	}
+	return answer
+}
+
+// DictionaryEntry groups the definitions of a word for one part of speech,
+// as shown in the define: box.
+type DictionaryEntry struct {
+	Word         string
+	Phonetic     string
+	PartOfSpeech string
+	Definitions  []DictionaryDefinition
+}
+
+type DictionaryDefinition struct {
+	Text     string
+	Examples []string
+}
+
+func extractDefinitions(doc *goquery.Document) []DictionaryEntry {
+	box := doc.Find("div.lr_container").First()
+	if box.Length() == 0 {
+		return nil
+	}
+
+	word := normalizeSpace(box.Find("[data-dobid='hdw']").First().Text())
+	phonetic := normalizeSpace(box.Find("span.LTKOO, span.lr_dct_ph").First().Text())
+
+	var entries []DictionaryEntry
+	box.Find("span.YrbPuc, div[data-dobid='dfn']").Each(func(i int, s *goquery.Selection) {
+		if s.Is("span.YrbPuc") {
+			entries = append(entries, DictionaryEntry{
+				Word:         word,
+				Phonetic:     phonetic,
+				PartOfSpeech: normalizeSpace(s.Text()),
+			})
+			return
+		}
+
+		if len(entries) == 0 {
+			entries = append(entries, DictionaryEntry{Word: word, Phonetic: phonetic})
+		}
+		definition := DictionaryDefinition{Text: normalizeSpace(s.Text())}
+		s.Parent().Find("div.ubHt5c, div.vk_gy").Each(func(i int, example *goquery.Selection) {
+			if text := strings.Trim(normalizeSpace(example.Text()), `"“”`); text != "" {
+				definition.Examples = append(definition.Examples, text)
+			}
+		})
+		last := &entries[len(entries)-1]
+		last.Definitions = append(last.Definitions, definition)
+	})
+
+	var filtered []DictionaryEntry
+	for _, entry := range entries {
+		if len(entry.Definitions) > 0 {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
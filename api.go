@@ -0,0 +1,226 @@
+package googlesearch
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// APIServerOptions configures the handler returned by NewAPIHandler.
+type APIServerOptions struct {
+	// Search holds the defaults for every search; NumResults and Lang can
+	// be overridden per request.
+	Search SearchOptions
+	// Searcher, if set, runs every search with Searcher.With instead, so
+	// they share its session and pacing; Search is then ignored.
+	Searcher *Searcher
+	// Keys maps accepted API keys to their daily request quota, where zero
+	// means unlimited. With no keys the API is open.
+	Keys map[string]int
+	// MaxResults caps the n parameter; it defaults to 100.
+	MaxResults int
+}
+
+// NewAPIHandler returns an HTTP handler serving
+//
+//	GET /search?q=golang&n=20&lang=en
+//
+// as JSON. With stream=1 results are sent as server-sent events ("result"
+// events followed by "done" or "error") as soon as each page is parsed.
+// A search failing halfway answers with the results found so far and an
+// error field. The API key is read from the X-API-Key header or the
+// api_key parameter; only valid requests count against its quota.
+func NewAPIHandler(opts APIServerOptions) http.Handler {
+	if opts.MaxResults <= 0 {
+		opts.MaxResults = 100
+	}
+	api := &apiServer{opts: opts, usage: make(map[string]int)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", api.search)
+	return mux
+}
+
+type apiServer struct {
+	opts APIServerOptions
+
+	mu       sync.Mutex
+	usageDay string
+	usage    map[string]int
+}
+
+// apiResponse is the body of a non-streamed search. Error is set when the
+// search failed after Results were collected.
+type apiResponse struct {
+	Query   string          `json:"query"`
+	Results []WebhookResult `json:"results"`
+	Error   string          `json:"error,omitempty"`
+}
+
+func (a *apiServer) search(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apiError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	key, quota, ok := a.authenticate(r)
+	if !ok {
+		apiError(w, http.StatusUnauthorized, "invalid API key")
+		return
+	}
+
+	q := r.URL.Query()
+	query := q.Get("q")
+	if query == "" {
+		apiError(w, http.StatusBadRequest, "missing q parameter")
+		return
+	}
+	opts := a.opts.Search
+	opts.NumResults = 10
+	if n := q.Get("n"); n != "" {
+		v, err := strconv.Atoi(n)
+		if err != nil || v <= 0 {
+			apiError(w, http.StatusBadRequest, "invalid n parameter")
+			return
+		}
+		opts.NumResults = v
+	}
+	if opts.NumResults > a.opts.MaxResults {
+		opts.NumResults = a.opts.MaxResults
+	}
+	if lang := q.Get("lang"); lang != "" {
+		parsed, err := ParseLanguage(lang)
+		if err != nil {
+			apiError(w, http.StatusBadRequest, "invalid lang parameter")
+			return
+		}
+		opts.Lang = parsed
+	}
+
+	var searcher *Searcher
+	if a.opts.Searcher != nil {
+		var err error
+		searcher, err = a.opts.Searcher.With(QueryOverrides{NumResults: opts.NumResults, Lang: opts.Lang})
+		if err != nil {
+			apiError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	// Only valid requests are charged against the quota.
+	if !a.charge(key, quota) {
+		apiError(w, http.StatusTooManyRequests, "daily quota exceeded")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	var stream <-chan SearchResponse
+	if searcher != nil {
+		stream = searcher.SearchStream(ctx, query)
+	} else {
+		stream = SearchStream(ctx, query, opts)
+	}
+	if q.Get("stream") == "1" {
+		a.serveEvents(w, stream)
+		return
+	}
+
+	resp := apiResponse{Query: query, Results: []WebhookResult{}}
+	status := http.StatusOK
+	for item := range stream {
+		if item.Err != nil {
+			// Like the event stream, keep the results delivered before
+			// the error.
+			status, resp.Error = apiStatus(item.Err), item.Err.Error()
+			break
+		}
+		resp.Results = append(resp.Results, newWebhookResult(item))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (a *apiServer) serveEvents(w http.ResponseWriter, stream <-chan SearchResponse) {
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	send := func(event string, v interface{}) {
+		data, _ := json.Marshal(v)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	for item := range stream {
+		if item.Err != nil {
+			send("error", map[string]string{"error": item.Err.Error()})
+			return
+		}
+		send("result", newWebhookResult(item))
+	}
+	send("done", struct{}{})
+}
+
+// authenticate returns the API key of r and its quota, and reports whether
+// the key is accepted. Keys are compared in constant time, so response
+// times do not reveal how much of a key was guessed right.
+func (a *apiServer) authenticate(r *http.Request) (string, int, bool) {
+	if len(a.opts.Keys) == 0 {
+		return "", 0, true
+	}
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		key = r.URL.Query().Get("api_key")
+	}
+	var (
+		matched string
+		quota   int
+		ok      bool
+	)
+	for candidate, candidateQuota := range a.opts.Keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(candidate)) == 1 {
+			matched, quota, ok = candidate, candidateQuota, true
+		}
+	}
+	return matched, quota, ok
+}
+
+// charge counts one request against the quota of key for the current UTC
+// day and reports whether the quota allowed it.
+func (a *apiServer) charge(key string, quota int) bool {
+	if len(a.opts.Keys) == 0 {
+		return true
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if day := time.Now().UTC().Format("2006-01-02"); day != a.usageDay {
+		a.usageDay = day
+		a.usage = make(map[string]int)
+	}
+	if quota > 0 && a.usage[key] >= quota {
+		return false
+	}
+	a.usage[key]++
+	return true
+}
+
+func apiStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrBlocked), errors.Is(err, ErrClosed):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+func apiError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,108 @@
+package googlesearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIHandlerSearcher(t *testing.T) {
+	google := newFakeGoogle(t, "lite")
+	proxy := newFakeProxy(t, google)
+	s, err := NewSearcher(SearchOptions{Proxy: proxy.URL, InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewAPIHandler(APIServerOptions{Searcher: s})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=golang&n=3&lang=de", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp apiResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 3 {
+		t.Errorf("got %d results, want 3", len(resp.Results))
+	}
+	if s.BytesDownloaded() == 0 {
+		t.Error("the search did not run on the Searcher's session")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=golang&lang=klingon", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid lang: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	s.Close(context.Background())
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=golang", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("after Close: status %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestAPIHandlerQuotaChargesValidRequests(t *testing.T) {
+	google := newFakeGoogle(t, "lite")
+	proxy := newFakeProxy(t, google)
+	handler := NewAPIHandler(APIServerOptions{
+		Search: SearchOptions{Proxy: proxy.URL, InsecureSkipVerify: true},
+		Keys:   map[string]int{"secret": 1},
+	})
+	get := func(target string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("X-API-Key", "secret")
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for _, target := range []string{"/search", "/search?q=golang&n=-1", "/search?q=golang&lang=klingon"} {
+		if code := get(target); code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want %d", target, code, http.StatusBadRequest)
+		}
+	}
+	if code := get("/search?q=golang&n=3"); code != http.StatusOK {
+		t.Errorf("first valid request: status %d, want %d after bad requests", code, http.StatusOK)
+	}
+	if code := get("/search?q=golang&n=3"); code != http.StatusTooManyRequests {
+		t.Errorf("second valid request: status %d, want %d", code, http.StatusTooManyRequests)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=golang&api_key=secreT", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong key: status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAPIHandlerKeepsResultsBeforeError(t *testing.T) {
+	lite, captcha := servePage(t, "lite"), servePage(t, "captcha")
+	google := newFakeGoogleFunc(t, func(w http.ResponseWriter, r *http.Request) {
+		if start := r.URL.Query().Get("start"); start != "" && start != "0" {
+			captcha(w, r)
+			return
+		}
+		lite(w, r)
+	})
+	proxy := newFakeProxy(t, google)
+	handler := NewAPIHandler(APIServerOptions{Search: SearchOptions{Proxy: proxy.URL, InsecureSkipVerify: true}})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=golang&n=9", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d for a block on the second page", rec.Code, http.StatusServiceUnavailable)
+	}
+	var resp apiResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 3 || resp.Error == "" {
+		t.Errorf("got %d results and error %q, want the first page's 3 and the block", len(resp.Results), resp.Error)
+	}
+}
@@ -0,0 +1,124 @@
+package googlesearch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// archiveTimeFormat sorts lexically in chronological order.
+const archiveTimeFormat = "20060102T150405.000000000Z"
+
+// Archiver stores the raw HTML of every SERP a search receives, gzip
+// compressed, in a Store keyed by query and fetch time. Archived pages can
+// later be re-parsed with newer versions of the parser or audited.
+type Archiver struct {
+	Store Store
+	// Prefix is prepended to all keys; it defaults to "archive/".
+	Prefix string
+	// OnError is called when a page could not be archived. Archiving
+	// never fails the search itself.
+	OnError func(error)
+}
+
+// Snapshot identifies one archived page.
+type Snapshot struct {
+	Key       string
+	Query     string
+	FetchedAt time.Time
+	Start     int
+}
+
+func (a *Archiver) prefix() string {
+	if a.Prefix == "" {
+		return "archive/"
+	}
+	return a.Prefix
+}
+
+func (a *Archiver) queryPrefix(query string) string {
+	return a.prefix() + url.PathEscape(query) + "/"
+}
+
+// Archive stores html as the page of query starting at start.
+func (a *Archiver) Archive(ctx context.Context, query string, start int, fetchedAt time.Time, html []byte) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(html); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s%s-%d.html.gz", a.queryPrefix(query), fetchedAt.UTC().Format(archiveTimeFormat), start)
+	return a.Store.Put(ctx, key, buf.Bytes())
+}
+
+// Snapshots lists the archived pages of query, oldest first.
+func (a *Archiver) Snapshots(ctx context.Context, query string) ([]Snapshot, error) {
+	prefix := a.queryPrefix(query)
+	keys, err := a.Store.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []Snapshot
+	for _, key := range keys {
+		name := strings.TrimSuffix(strings.TrimPrefix(key, prefix), ".html.gz")
+		i := strings.LastIndex(name, "-")
+		if i < 0 {
+			continue
+		}
+		fetchedAt, err := time.Parse(archiveTimeFormat, name[:i])
+		if err != nil {
+			continue
+		}
+		start, err := strconv.Atoi(name[i+1:])
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{Key: key, Query: query, FetchedAt: fetchedAt, Start: start})
+	}
+	return snapshots, nil
+}
+
+// Load returns the decompressed HTML of snapshot.
+func (a *Archiver) Load(ctx context.Context, snapshot Snapshot) ([]byte, error) {
+	data, ok, err := a.Store.Get(ctx, snapshot.Key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("google: snapshot %s not found", snapshot.Key)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// Reparse runs the current parser over an archived page.
+func (a *Archiver) Reparse(ctx context.Context, snapshot Snapshot) ([]SearchResult, *SERPMetadata, error) {
+	html, err := a.Load(ctx, snapshot)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ParseHTML(bytes.NewReader(html))
+}
+
+// archive is called for every fetched page when an Archiver is configured.
+func (a *Archiver) archive(ctx context.Context, query string, start int, html []byte) {
+	if a == nil || a.Store == nil {
+		return
+	}
+	if err := a.Archive(ctx, query, start, time.Now(), html); err != nil && a.OnError != nil {
+		a.OnError(err)
+	}
+}
@@ -0,0 +1,201 @@
+package googlesearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Audit outcomes.
+const (
+	AuditOK        = "ok"
+	AuditRedirect  = "redirect"
+	AuditBlocked   = "blocked"
+	AuditConsent   = "consent"
+	AuditHTTPError = "http_error"
+	AuditError     = "error"
+)
+
+// AuditLog records every outbound request of the searches it is attached
+// to (SearchOptions.AuditLog), including warm-ups, consent forms,
+// redirects and suggestions, so operators can show what was requested,
+// when and through which proxy. Each record is written as a JSON line to
+// Writer and, if Store is set, as its own key below Prefix. Writer is
+// written before the request returns, so it should be fast, such as a
+// buffered file; Store writes run in the background, so a slow Store does
+// not hold up requests, and Flush waits for them. It is safe for
+// concurrent use.
+type AuditLog struct {
+	Writer io.Writer
+	Store  Store
+	// Prefix is prepended to Store keys; it defaults to "audit/".
+	Prefix string
+	// OnError is called when a record could not be written. Auditing
+	// never fails the request itself.
+	OnError func(error)
+
+	mu      sync.Mutex
+	seq     int
+	pending sync.WaitGroup
+}
+
+// AuditRecord is one audited request.
+type AuditRecord struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	URL    string    `json:"url"`
+	// Query is set for results page requests, and Page, the 1-based
+	// number of the page within its search, for the pages of Search and
+	// the other paginating calls.
+	Query string `json:"query,omitempty"`
+	Page  int    `json:"page,omitempty"`
+	// Proxy has any password redacted.
+	Proxy     string `json:"proxy,omitempty"`
+	UserAgent string `json:"user_agent"`
+	Status    int    `json:"status,omitempty"`
+	// Outcome is one of the Audit constants; Error is set for AuditError.
+	Outcome    string `json:"outcome"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	Labels     Labels `json:"labels,omitempty"`
+}
+
+func (a *AuditLog) prefix() string {
+	if a.Prefix == "" {
+		return "audit/"
+	}
+	return a.Prefix
+}
+
+// Record writes rec to Writer and starts writing it to Store. The error is
+// Writer's; a failed Store write is passed to OnError.
+func (a *AuditLog) Record(ctx context.Context, rec AuditRecord) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(rec); err != nil {
+		return err
+	}
+	line := buf.Bytes()
+
+	var err error
+	a.mu.Lock()
+	a.seq++
+	seq := a.seq
+	if a.Writer != nil {
+		_, err = a.Writer.Write(line)
+	}
+	a.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if a.Store != nil {
+		at := rec.Time.UTC()
+		key := fmt.Sprintf("%s%s/%s-%06d.json", a.prefix(), at.Format("20060102"), at.Format(archiveTimeFormat), seq)
+		// The record must be kept even when the search was canceled.
+		ctx = context.WithoutCancel(ctx)
+		a.pending.Add(1)
+		go func() {
+			defer a.pending.Done()
+			if err := a.Store.Put(ctx, key, line); err != nil && a.OnError != nil {
+				a.OnError(err)
+			}
+		}()
+	}
+	return nil
+}
+
+// Flush waits until the records passed to Record so far are in Store, or
+// until ctx ends. Searcher.Close flushes the AuditLog of its options.
+func (a *AuditLog) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		a.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		select {
+		case <-done:
+			return nil
+		default:
+			return ctx.Err()
+		}
+	}
+}
+
+type pageNumContextKey struct{}
+
+// withPageNum tells the audit log which page of a search the requests
+// made with ctx are for. start does not tell: pages advance it by the
+// number of results they held.
+func withPageNum(ctx context.Context, pageNum int) context.Context {
+	return context.WithValue(ctx, pageNumContextKey{}, pageNum)
+}
+
+// pageNumFromContext returns the page number set by withPageNum, or 0.
+func pageNumFromContext(ctx context.Context) int {
+	pageNum, _ := ctx.Value(pageNumContextKey{}).(int)
+	return pageNum
+}
+
+// auditTransport writes an AuditRecord for every round trip.
+type auditTransport struct {
+	next   http.RoundTripper
+	log    *AuditLog
+	labels Labels
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	began := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	rec := AuditRecord{
+		Time:       began,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		UserAgent:  req.Header.Get("User-Agent"),
+		DurationMS: time.Since(began).Milliseconds(),
+		Labels:     mergeLabels(t.labels, LabelsFromContext(req.Context())),
+	}
+	if req.URL.Path == "/search" {
+		rec.Query = req.URL.Query().Get("q")
+		rec.Page = pageNumFromContext(req.Context())
+	}
+	if proxy, perr := proxyFromContext(req); perr == nil && proxy != nil {
+		rec.Proxy = proxy.Redacted()
+	}
+	switch {
+	case err != nil:
+		rec.Outcome, rec.Error = AuditError, err.Error()
+	default:
+		rec.Status = resp.StatusCode
+		if resp.Request == nil {
+			resp.Request = req
+		}
+		switch {
+		case isBlocked(resp, nil):
+			rec.Outcome = AuditBlocked
+		case isConsentPage(resp):
+			rec.Outcome = AuditConsent
+		case resp.StatusCode >= 300 && resp.StatusCode < 400:
+			rec.Outcome = AuditRedirect
+		case resp.StatusCode >= 400:
+			rec.Outcome = AuditHTTPError
+		default:
+			rec.Outcome = AuditOK
+		}
+	}
+
+	if werr := t.log.Record(req.Context(), rec); werr != nil && t.log.OnError != nil {
+		t.log.OnError(werr)
+	}
+	return resp, err
+}
@@ -0,0 +1,181 @@
+package googlesearch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingStore is a MemoryStore whose Put waits until release is closed.
+type blockingStore struct {
+	*MemoryStore
+	release chan struct{}
+}
+
+func (s blockingStore) Put(ctx context.Context, key string, value []byte) error {
+	<-s.release
+	return s.MemoryStore.Put(ctx, key, value)
+}
+
+func TestAuditLogRecordsSearch(t *testing.T) {
+	google := newFakeGoogle(t, "lite")
+	proxy := newFakeProxy(t, google)
+	var lines bytes.Buffer
+	store := NewMemoryStore()
+	audit := &AuditLog{Writer: &lines, Store: store}
+	s, err := NewSearcher(SearchOptions{
+		NumResults:         3,
+		Proxy:              proxy.URL,
+		InsecureSkipVerify: true,
+		AuditLog:           audit,
+		Labels:             Labels{"job": "audit"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Search(context.Background(), "golang"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(&lines)
+	for scanner.Scan() {
+		var rec AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) == 0 {
+		t.Fatal("no audit records were written")
+	}
+	var search *AuditRecord
+	for i := range records {
+		if records[i].Query != "" {
+			search = &records[i]
+		}
+	}
+	if search == nil {
+		t.Fatalf("no record of the results page among %+v", records)
+	}
+	if search.Query != "golang" || search.Page != 1 || search.Outcome != AuditOK || search.Status != 200 {
+		t.Errorf("results page record = %+v", *search)
+	}
+	if search.Labels["job"] != "audit" {
+		t.Errorf("record labels = %v, want the Searcher's labels", search.Labels)
+	}
+
+	keys, err := store.List(context.Background(), "audit/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != len(records) {
+		t.Errorf("Store holds %d records after Close, Writer got %d", len(keys), len(records))
+	}
+}
+
+func TestAuditLogPageNumbers(t *testing.T) {
+	lite, err := os.ReadFile(filepath.Join("testdata", "serp", "lite.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every page holds three results, fewer than num asks for, each page
+	// its own so pagination goes on.
+	google := newFakeGoogleFunc(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(strings.ReplaceAll(string(lite), "example", "example"+r.URL.Query().Get("start"))))
+	})
+	proxy := newFakeProxy(t, google)
+	var lines bytes.Buffer
+	s, err := NewSearcher(SearchOptions{
+		NumResults:         9,
+		Proxy:              proxy.URL,
+		InsecureSkipVerify: true,
+		AuditLog:           &AuditLog{Writer: &lines},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Search(context.Background(), "golang"); err != nil {
+		t.Fatal(err)
+	}
+
+	var pages []int
+	scanner := bufio.NewScanner(&lines)
+	for scanner.Scan() {
+		var rec AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Query != "" {
+			pages = append(pages, rec.Page)
+		}
+	}
+	if len(pages) != 3 || pages[0] != 1 || pages[1] != 2 || pages[2] != 3 {
+		t.Errorf("audited pages %v, want [1 2 3]", pages)
+	}
+}
+
+func TestAuditLogStoreDoesNotBlockRecord(t *testing.T) {
+	store := blockingStore{NewMemoryStore(), make(chan struct{})}
+	audit := &AuditLog{Store: store}
+	ctx := context.Background()
+
+	recorded := make(chan error, 1)
+	go func() { recorded <- audit.Record(ctx, AuditRecord{Time: time.Now(), Outcome: AuditOK}) }()
+	select {
+	case err := <-recorded:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Record waited for the Store")
+	}
+
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := audit.Flush(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Flush with a Store write pending = %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(store.release)
+	if err := audit.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := store.List(ctx, "audit/"); len(keys) != 1 {
+		t.Errorf("Store holds %d records after Flush, want 1", len(keys))
+	}
+}
+
+func TestAuditLogStoreError(t *testing.T) {
+	failed := make(chan error, 1)
+	audit := &AuditLog{Store: failingStore{}, OnError: func(err error) { failed <- err }}
+	if err := audit.Record(context.Background(), AuditRecord{Time: time.Now()}); err != nil {
+		t.Fatalf("Record returned the Store's error: %v", err)
+	}
+	audit.Flush(context.Background())
+	select {
+	case err := <-failed:
+		if !errors.Is(err, errStoreDown) {
+			t.Errorf("OnError got %v, want %v", err, errStoreDown)
+		}
+	default:
+		t.Error("OnError was not called for a failed Store write")
+	}
+}
+
+var errStoreDown = errors.New("store down")
+
+type failingStore struct{ Store }
+
+func (failingStore) Put(context.Context, string, []byte) error { return errStoreDown }
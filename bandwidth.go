@@ -0,0 +1,81 @@
+package googlesearch
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// byteMeterContextKey carries the per-search byte counter.
+type byteMeterContextKey struct{}
+
+func withByteMeter(ctx context.Context, n *atomic.Int64) context.Context {
+	return context.WithValue(ctx, byteMeterContextKey{}, n)
+}
+
+// meterTransport counts the response body bytes as they come over the
+// wire, before decompression, which is what metered proxies bill. It asks
+// for gzip itself, like http.Transport does, so it can count the
+// compressed size and still hand out decoded bodies.
+type meterTransport struct {
+	next  http.RoundTripper
+	total *atomic.Int64
+}
+
+func (t *meterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	decode := false
+	if req.Header.Get("Accept-Encoding") == "" && req.Header.Get("Range") == "" && req.Method != http.MethodHead {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", "gzip")
+		decode = true
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	counters := []*atomic.Int64{t.total}
+	if n, ok := req.Context().Value(byteMeterContextKey{}).(*atomic.Int64); ok {
+		counters = append(counters, n)
+	}
+	counted := &countingBody{ReadCloser: resp.Body, counters: counters}
+	resp.Body = counted
+	if decode && resp.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(counted)
+		if err != nil {
+			counted.Close()
+			return nil, err
+		}
+		resp.Body = &gzipBody{Reader: zr, body: counted}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+	return resp, nil
+}
+
+type countingBody struct {
+	io.ReadCloser
+	counters []*atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	for _, c := range b.counters {
+		c.Add(int64(n))
+	}
+	return n, err
+}
+
+type gzipBody struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
@@ -0,0 +1,111 @@
+package googlesearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const directConnection = "direct"
+
+// BlockRegistry remembers which proxies, or the direct connection, were
+// recently served a captcha and keeps them out of rotation for a cooldown
+// period. State lives in a Cache so several processes can share it.
+type BlockRegistry struct {
+	cache    Cache
+	cooldown time.Duration
+}
+
+// NewBlockRegistry returns a registry storing its entries in cache. A nil
+// cache uses a private MemoryCache; cooldown defaults to 30 minutes.
+func NewBlockRegistry(cache Cache, cooldown time.Duration) *BlockRegistry {
+	if cache == nil {
+		cache = NewMemoryCache()
+	}
+	if cooldown <= 0 {
+		cooldown = 30 * time.Minute
+	}
+	return &BlockRegistry{cache: cache, cooldown: cooldown}
+}
+
+// MarkBlocked starts the cooldown for proxy. A nil proxy stands for the
+// direct connection.
+func (r *BlockRegistry) MarkBlocked(ctx context.Context, proxy *url.URL) error {
+	until := time.Now().Add(r.cooldown)
+	return r.cache.Set(ctx, blockKey(proxy), []byte(until.Format(time.RFC3339Nano)), r.cooldown)
+}
+
+// BlockedUntil returns the end of the cooldown for proxy, or the zero time
+// if it is usable.
+func (r *BlockRegistry) BlockedUntil(ctx context.Context, proxy *url.URL) (time.Time, error) {
+	value, ok, err := r.cache.Get(ctx, blockKey(proxy))
+	if err != nil || !ok {
+		return time.Time{}, err
+	}
+	until, err := time.Parse(time.RFC3339Nano, string(value))
+	if err != nil || time.Now().After(until) {
+		return time.Time{}, nil
+	}
+	return until, nil
+}
+
+// Clear ends the cooldown for proxy early.
+func (r *BlockRegistry) Clear(ctx context.Context, proxy *url.URL) error {
+	return r.cache.Delete(ctx, blockKey(proxy))
+}
+
+func blockKey(proxy *url.URL) string {
+	if proxy == nil {
+		return "googlesearch:block:" + directConnection
+	}
+	return "googlesearch:block:" + proxy.Redacted()
+}
+
+// maxBlockedProxySkips bounds how many cooling-down proxies are skipped
+// before a request gives up.
+const maxBlockedProxySkips = 5
+
+// nextProxy asks the ProxyProvider for a proxy, skipping ones that are in
+// cooldown according to the BlockRegistry.
+func (s *session) nextProxy(ctx context.Context) (*url.URL, func(bool), error) {
+	registry := s.opts.BlockRegistry
+	for i := 0; ; i++ {
+		proxy, release := s.opts.ProxyProvider.Next(ctx)
+		if registry == nil {
+			return proxy, release, nil
+		}
+
+		until, err := registry.BlockedUntil(ctx, proxy)
+		if err != nil || until.IsZero() {
+			return proxy, release, nil
+		}
+		// The proxy was never used, so it has no outcome to release
+		// with; a release(false) would count as a failure.
+		if skipper, ok := s.opts.ProxyProvider.(ProxySkipReporter); ok && proxy != nil {
+			skipper.ReportSkipped(proxy)
+		}
+		if proxy == nil || i+1 >= maxBlockedProxySkips {
+			name := directConnection
+			if proxy != nil {
+				name = proxy.Redacted()
+			}
+			return nil, nil, fmt.Errorf("%w: %s cooling down until %s", ErrBlocked, name, until.Format(time.RFC3339))
+		}
+	}
+}
+
+// reportBlock starts the cooldown for proxy when err says it was blocked
+// and tells the ProxyProvider if it wants to know.
+func (s *session) reportBlock(ctx context.Context, proxy *url.URL, err error) {
+	if !errors.Is(err, ErrBlocked) {
+		return
+	}
+	if s.opts.BlockRegistry != nil {
+		s.opts.BlockRegistry.MarkBlocked(ctx, proxy)
+	}
+	if reporter, ok := s.opts.ProxyProvider.(ProxyBlockReporter); ok && proxy != nil {
+		reporter.ReportBlocked(proxy)
+	}
+}
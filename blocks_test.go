@@ -0,0 +1,47 @@
+package googlesearch
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNextProxySkipsWithoutFailure(t *testing.T) {
+	pool, err := NewProxyPool("http://blocked.example:8080", "http://good.example:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewBlockRegistry(nil, time.Hour)
+	ctx := context.Background()
+	blocked, _ := url.Parse("http://blocked.example:8080")
+	if err := registry.MarkBlocked(ctx, blocked); err != nil {
+		t.Fatal(err)
+	}
+	s := &session{opts: SearchOptions{ProxyProvider: pool, BlockRegistry: registry}}
+
+	proxy, release, err := s.nextProxy(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proxy.Host != "good.example:8080" {
+		t.Fatalf("nextProxy returned %v, want the proxy that is not cooling down", proxy)
+	}
+	release(true)
+
+	for _, stats := range pool.Stats() {
+		if stats.Failures != 0 {
+			t.Errorf("%s has %d failures after being skipped", stats.Proxy, stats.Failures)
+		}
+		switch stats.Proxy {
+		case blocked.Redacted():
+			if stats.Requests != 0 || !stats.LastUsed.IsZero() {
+				t.Errorf("the skipped proxy counts %d requests, last used %v", stats.Requests, stats.LastUsed)
+			}
+		default:
+			if stats.Requests != 1 || stats.Successes != 1 || stats.LastUsed.IsZero() {
+				t.Errorf("the used proxy counts %d requests and %d successes, last used %v", stats.Requests, stats.Successes, stats.LastUsed)
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package googlesearch
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// URLCategory is the kind of page a result URL points to, as guessed by
+// ClassifyURL.
+type URLCategory string
+
+const (
+	CategoryHomepage URLCategory = "homepage"
+	CategoryArticle  URLCategory = "article"
+	CategoryForum    URLCategory = "forum"
+	CategorySocial   URLCategory = "social"
+	CategoryVideo    URLCategory = "video"
+	// CategoryOther covers shallow pages such as "/pricing" or "/about"
+	// and URLs that could not be parsed.
+	CategoryOther URLCategory = "other"
+)
+
+// videoPages matches the paths of video pages on video sites.
+var videoPages = map[string]*regexp.Regexp{
+	"youtube.com":     regexp.MustCompile(`^/(watch|shorts/|embed/|live/)`),
+	"youtu.be":        regexp.MustCompile(`^/[\w-]+$`),
+	"vimeo.com":       regexp.MustCompile(`^/(video/)?\d+`),
+	"dailymotion.com": regexp.MustCompile(`^/video/`),
+	"twitch.tv":       regexp.MustCompile(`^/videos/`),
+	"tiktok.com":      regexp.MustCompile(`/video/\d+`),
+	"bilibili.com":    regexp.MustCompile(`^/video/`),
+	"ted.com":         regexp.MustCompile(`^/talks/`),
+}
+
+// forumDomains are discussion sites; every deep page on them is a thread.
+var forumDomains = map[string]bool{
+	"reddit.com":        true,
+	"quora.com":         true,
+	"stackoverflow.com": true,
+	"stackexchange.com": true,
+	"superuser.com":     true,
+	"serverfault.com":   true,
+	"askubuntu.com":     true,
+	"ycombinator.com":   true,
+	"lobste.rs":         true,
+	"discourse.org":     true,
+}
+
+// socialDomains are social networks; their deep pages are profiles or
+// posts.
+var socialDomains = map[string]bool{
+	"twitter.com":     true,
+	"x.com":           true,
+	"facebook.com":    true,
+	"instagram.com":   true,
+	"linkedin.com":    true,
+	"tiktok.com":      true,
+	"pinterest.com":   true,
+	"threads.net":     true,
+	"bsky.app":        true,
+	"tumblr.com":      true,
+	"mastodon.social": true,
+}
+
+var (
+	forumHostPrefixes = []string{"forum.", "forums.", "community.", "discuss.", "discussions.", "answers."}
+	forumPath         = regexp.MustCompile(`(?i)(^|/)(forums?|threads?|topics?|t|discussions?|questions|boards?|community)/|viewtopic\.php|showthread\.php`)
+	youtubeChannel    = regexp.MustCompile(`^/(@[^/]+|channel/|c/|user/)`)
+	articleDate       = regexp.MustCompile(`/(19|20)\d\d/`)
+	languageSegment   = regexp.MustCompile(`^[a-z]{2}([-_][a-z]{2})?$`)
+	indexPage         = regexp.MustCompile(`(?i)^index\.(html?|php|aspx?)$`)
+)
+
+// ClassifyURL guesses from its host and path what kind of page rawURL is.
+// It works on the URL alone, so it is cheap but approximate: a deep page
+// on an unknown site is assumed to be an article.
+func ClassifyURL(rawURL string) URLCategory {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return CategoryOther
+	}
+	host := strings.ToLower(u.Hostname())
+	domain := registrableDomain(rawURL)
+
+	var segments []string
+	for _, segment := range strings.Split(u.EscapedPath(), "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) > 0 && indexPage.MatchString(segments[len(segments)-1]) {
+		segments = segments[:len(segments)-1]
+	}
+	if u.RawQuery == "" && (len(segments) == 0 || len(segments) == 1 && languageSegment.MatchString(strings.ToLower(segments[0]))) {
+		return CategoryHomepage
+	}
+
+	p := "/" + strings.Join(segments, "/")
+	if re, ok := videoPages[domain]; ok && re.MatchString(p) {
+		return CategoryVideo
+	}
+
+	if forumDomains[domain] || forumPath.MatchString(p) {
+		return CategoryForum
+	}
+	for _, prefix := range forumHostPrefixes {
+		if strings.HasPrefix(host, prefix) {
+			return CategoryForum
+		}
+	}
+
+	if socialDomains[domain] || domain == "youtube.com" && youtubeChannel.MatchString(p) {
+		return CategorySocial
+	}
+
+	if len(segments) == 0 {
+		return CategoryOther
+	}
+	last := segments[len(segments)-1]
+	if len(segments) >= 2 || articleDate.MatchString(p+"/") ||
+		strings.ContainsAny(strings.TrimSuffix(last, path.Ext(last)), "-_") {
+		return CategoryArticle
+	}
+	return CategoryOther
+}
+
+// FilterCategories returns the results whose Category is one of
+// categories.
+func FilterCategories(results []SearchResult, categories ...URLCategory) []SearchResult {
+	var kept []SearchResult
+	for _, result := range results {
+		for _, category := range categories {
+			if result.Category == category {
+				kept = append(kept, result)
+				break
+			}
+		}
+	}
+	return kept
+}
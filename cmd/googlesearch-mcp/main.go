@@ -0,0 +1,164 @@
+// Command googlesearch-mcp is a Model Context Protocol server that exposes
+// a "google_search" tool to LLM agents. It speaks JSON-RPC 2.0 over stdin
+// and stdout, one message per line.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/1hehaq/googlesearch"
+)
+
+const protocolVersion = "2024-11-05"
+
+const toolSchema = `{
+  "type": "object",
+  "properties": {
+    "query": {"type": "string", "description": "The search query."},
+    "num_results": {"type": "integer", "minimum": 1, "maximum": 50, "default": 10, "description": "Number of results to return."},
+    "language": {"type": "string", "description": "Interface language code, e.g. \"en\" or \"de\"."},
+    "time_range": {"type": "string", "enum": ["any", "hour", "day", "week", "month", "year"], "description": "Only return pages indexed within this period."}
+  },
+  "required": ["query"]
+}`
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type searchArgs struct {
+	Query      string `json:"query"`
+	NumResults int    `json:"num_results"`
+	Language   string `json:"language"`
+	TimeRange  string `json:"time_range"`
+}
+
+type server struct {
+	opts googlesearch.SearchOptions
+}
+
+func main() {
+	proxy := flag.String("proxy", "", "proxy URL for outgoing requests")
+	lang := flag.String("lang", "en", "default language")
+	flag.Parse()
+	log.SetOutput(os.Stderr)
+
+	srv := &server{opts: googlesearch.SearchOptions{Proxy: *proxy, Lang: googlesearch.Language(*lang), Timeout: 10}}
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	out := json.NewEncoder(os.Stdout)
+	for in.Scan() {
+		var req request
+		if err := json.Unmarshal(in.Bytes(), &req); err != nil {
+			out.Encode(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{-32700, "parse error"}})
+			continue
+		}
+		result, rerr := srv.handle(context.Background(), req)
+		if req.ID == nil {
+			// Notifications get no response.
+			continue
+		}
+		out.Encode(response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rerr})
+	}
+	if err := in.Err(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func (s *server) handle(ctx context.Context, req request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"protocolVersion": protocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": "googlesearch", "version": "1.0.0"},
+		}, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{
+			"tools": []map[string]interface{}{{
+				"name":        "google_search",
+				"description": "Search Google and return the top organic results with title, URL and snippet.",
+				"inputSchema": json.RawMessage(toolSchema),
+			}},
+		}, nil
+	case "tools/call":
+		var params struct {
+			Name      string     `json:"name"`
+			Arguments searchArgs `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{-32602, "invalid params: " + err.Error()}
+		}
+		if params.Name != "google_search" {
+			return nil, &rpcError{-32602, "unknown tool " + params.Name}
+		}
+		text, err := s.search(ctx, params.Arguments)
+		if err != nil {
+			return toolResult(err.Error(), true), nil
+		}
+		return toolResult(text, false), nil
+	}
+	if strings.HasPrefix(req.Method, "notifications/") {
+		return nil, nil
+	}
+	return nil, &rpcError{-32601, "method not found: " + req.Method}
+}
+
+func (s *server) search(ctx context.Context, args searchArgs) (string, error) {
+	if strings.TrimSpace(args.Query) == "" {
+		return "", fmt.Errorf("query is required")
+	}
+	opts := s.opts
+	opts.NumResults = args.NumResults
+	if opts.NumResults <= 0 {
+		opts.NumResults = 10
+	}
+	if opts.NumResults > 50 {
+		opts.NumResults = 50
+	}
+	if args.Language != "" {
+		opts.Lang = googlesearch.Language(args.Language)
+	}
+	tr, err := googlesearch.ParseTimeRange(args.TimeRange)
+	if err != nil {
+		return "", err
+	}
+	opts.TimeRange = tr
+
+	results, _, err := googlesearch.SearchWithMetadata(ctx, args.Query, opts)
+	if err != nil {
+		return "", err
+	}
+	return googlesearch.FormatText(results), nil
+}
+
+func toolResult(text string, isError bool) map[string]interface{} {
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// subcommands lists the words completed in first position.
+var subcommands = []string{"cache", "completion", "diff", "history", "tui"}
+
+// completion prints a completion script for the given shell, e.g.
+//
+//	source <(googlesearch completion bash)
+func completion(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: googlesearch completion bash|zsh|fish")
+	}
+	flags := searchFlagNames()
+	switch args[0] {
+	case "bash":
+		writeBash(os.Stdout, flags)
+	case "zsh":
+		writeZsh(os.Stdout, flags)
+	case "fish":
+		writeFish(os.Stdout, flags)
+	default:
+		return fmt.Errorf("unsupported shell %q", args[0])
+	}
+	return nil
+}
+
+type flagInfo struct {
+	name, usage string
+}
+
+// searchFlagNames returns the flags of the search command, so the scripts
+// never drift from the real flag set.
+func searchFlagNames() []flagInfo {
+	fs := flag.NewFlagSet("googlesearch", flag.ContinueOnError)
+	var sf searchFlags
+	sf.register(fs)
+	fs.String("format", "", "output template or built-in format")
+	fs.String("serp-format", "", "template for the whole SERP")
+
+	var flags []flagInfo
+	fs.VisitAll(func(f *flag.Flag) {
+		_, usage := flag.UnquoteUsage(f)
+		flags = append(flags, flagInfo{f.Name, usage})
+	})
+	sort.Slice(flags, func(i, j int) bool { return flags[i].name < flags[j].name })
+	return flags
+}
+
+func writeBash(w io.Writer, flags []flagInfo) {
+	var words []string
+	for _, f := range flags {
+		words = append(words, "--"+f.name)
+	}
+	fmt.Fprintf(w, `_googlesearch() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    if [[ $COMP_CWORD -eq 1 && "$cur" != -* ]]; then
+        COMPREPLY=($(compgen -W "%s" -- "$cur"))
+        return
+    fi
+    case "${COMP_WORDS[1]}" in
+    completion) COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur")); return ;;
+    cache) COMPREPLY=($(compgen -W "ls clear" -- "$cur")); return ;;
+    esac
+    case "${COMP_WORDS[COMP_CWORD-1]}" in
+    --format) COMPREPLY=($(compgen -W "markdown org csv urls" -- "$cur")); return ;;
+    esac
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "%s" -- "$cur"))
+    fi
+}
+complete -F _googlesearch googlesearch
+`, strings.Join(subcommands, " "), strings.Join(words, " "))
+}
+
+func writeZsh(w io.Writer, flags []flagInfo) {
+	fmt.Fprintln(w, "#compdef googlesearch")
+	fmt.Fprintln(w, "_arguments \\")
+	for _, f := range flags {
+		spec := fmt.Sprintf("--%s[%s]", f.name, zshEscape(f.usage))
+		if f.name == "format" {
+			spec += ":format:(markdown org csv urls)"
+		} else {
+			spec += ":value:"
+		}
+		fmt.Fprintf(w, "  '%s' \\\n", spec)
+	}
+	fmt.Fprintf(w, "  '1:command or query:(%s)' \\\n", strings.Join(subcommands, " "))
+	fmt.Fprintln(w, "  '*:query:'")
+}
+
+func writeFish(w io.Writer, flags []flagInfo) {
+	for _, sub := range subcommands {
+		fmt.Fprintf(w, "complete -c googlesearch -n '__fish_use_subcommand' -a %s\n", sub)
+	}
+	fmt.Fprintln(w, "complete -c googlesearch -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'")
+	fmt.Fprintln(w, "complete -c googlesearch -n '__fish_seen_subcommand_from cache' -a 'ls clear'")
+	for _, f := range flags {
+		line := fmt.Sprintf("complete -c googlesearch -l %s -r -d '%s'", f.name, fishEscape(f.usage))
+		if f.name == "format" {
+			line += " -a 'markdown org csv urls'"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+func zshEscape(s string) string {
+	return strings.NewReplacer("'", "'\\''", "[", "\\[", "]", "\\]", ":", "\\:").Replace(s)
+}
+
+func fishEscape(s string) string {
+	return strings.ReplaceAll(s, "'", "\\'")
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/1hehaq/googlesearch"
+)
+
+// historyEntry is one line of the history file.
+type historyEntry struct {
+	Time    time.Time       `json:"time"`
+	Query   string          `json:"query"`
+	Results []historyResult `json:"results"`
+}
+
+type historyResult struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+// dataDir returns the directory holding the CLI's cache and history.
+func dataDir() (string, error) {
+	if dir := os.Getenv("GOOGLESEARCH_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "googlesearch"), nil
+}
+
+func openCache() (*googlesearch.FileCache, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return nil, err
+	}
+	return googlesearch.NewFileCache(filepath.Join(dir, "serp"))
+}
+
+func historyPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), os.MkdirAll(dir, 0o700)
+}
+
+func appendHistory(query string, results []googlesearch.SearchResult) error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	entry := historyEntry{Time: time.Now(), Query: query}
+	for _, r := range results {
+		entry.Results = append(entry.Results, historyResult{URL: r.URL, Title: r.Title})
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readHistory returns the history entries for query, or all entries when
+// query is empty, oldest first.
+func readHistory(query string) ([]historyEntry, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []historyEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		var entry historyEntry
+		if json.Unmarshal(sc.Bytes(), &entry) != nil {
+			continue
+		}
+		if query == "" || entry.Query == query {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, sc.Err()
+}
+
+// historyCmd implements "googlesearch history [-n N] [query]".
+func historyCmd(args []string) error {
+	fs := flag.NewFlagSet("googlesearch history", flag.ExitOnError)
+	n := fs.Int("n", 20, "number of entries to show")
+	fs.Parse(args)
+
+	entries, err := readHistory(strings.Join(fs.Args(), " "))
+	if err != nil {
+		return err
+	}
+	if len(entries) > *n {
+		entries = entries[len(entries)-*n:]
+	}
+	for _, e := range entries {
+		fmt.Printf("%s  %3d results  %s\n", e.Time.Local().Format("2006-01-02 15:04"), len(e.Results), e.Query)
+	}
+	return nil
+}
+
+// cacheCmd implements "googlesearch cache ls|clear".
+func cacheCmd(args []string) error {
+	if len(args) != 1 || (args[0] != "ls" && args[0] != "clear") {
+		return fmt.Errorf("usage: googlesearch cache ls|clear")
+	}
+	cache, err := openCache()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if args[0] == "clear" {
+		return cache.Clear(ctx)
+	}
+
+	entries, err := cache.Entries(ctx)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		var serp struct {
+			Query    string
+			Results  []json.RawMessage
+			StoredAt time.Time
+		}
+		if json.Unmarshal(e.Value, &serp) != nil || serp.Query == "" {
+			fmt.Printf("%-20s %8d bytes  %s\n", "", e.Size, e.Key)
+			continue
+		}
+		fmt.Printf("%s  %3d results  %7d bytes  expires %s  %s\n",
+			serp.StoredAt.Local().Format("2006-01-02 15:04"), len(serp.Results), e.Size,
+			e.Expires.Local().Format("2006-01-02 15:04"), serp.Query)
+	}
+	return nil
+}
+
+// diffCmd implements "googlesearch diff <query>", comparing the last two
+// recorded runs of query.
+func diffCmd(args []string) error {
+	query := strings.Join(args, " ")
+	if query == "" {
+		return fmt.Errorf("usage: googlesearch diff <query>")
+	}
+	entries, err := readHistory(query)
+	if err != nil {
+		return err
+	}
+	if len(entries) < 2 {
+		return fmt.Errorf("need at least two recorded runs of %q, have %d", query, len(entries))
+	}
+	prev, cur := entries[len(entries)-2], entries[len(entries)-1]
+	fmt.Printf("--- %s\n+++ %s\n", prev.Time.Local().Format(time.RFC3339), cur.Time.Local().Format(time.RFC3339))
+
+	before := make(map[string]int, len(prev.Results))
+	for i, r := range prev.Results {
+		before[r.URL] = i + 1
+	}
+	changed := false
+	seen := make(map[string]bool, len(cur.Results))
+	for i, r := range cur.Results {
+		seen[r.URL] = true
+		rank, ok := before[r.URL]
+		switch {
+		case !ok:
+			fmt.Printf("+ %2d  %s\n", i+1, r.URL)
+		case rank != i+1:
+			fmt.Printf("~ %2d  %s (was %d)\n", i+1, r.URL, rank)
+		default:
+			continue
+		}
+		changed = true
+	}
+	for i, r := range prev.Results {
+		if !seen[r.URL] {
+			fmt.Printf("- %2d  %s\n", i+1, r.URL)
+			changed = true
+		}
+	}
+	if !changed {
+		fmt.Println("no changes")
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+// Command googlesearch runs a Google search from the command line.
+//
+//	googlesearch [flags] query...
+//	googlesearch tui [flags] [query...]
+//	googlesearch history [-n N] [query...]
+//	googlesearch cache ls|clear
+//	googlesearch diff query...
+//	googlesearch completion bash|zsh|fish
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/1hehaq/googlesearch"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "googlesearch:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "completion":
+			return completion(args[1:])
+		case "tui":
+			return tui(args[1:])
+		case "history":
+			return historyCmd(args[1:])
+		case "cache":
+			return cacheCmd(args[1:])
+		case "diff":
+			return diffCmd(args[1:])
+		}
+	}
+
+	fs := flag.NewFlagSet("googlesearch", flag.ExitOnError)
+	var sf searchFlags
+	sf.register(fs)
+	format := fs.String("format", "", "per-result Go template, a built-in format (markdown, org, csv, urls) or @file")
+	serpFormat := fs.String("serp-format", "", "template for the whole SERP, or @file; the per-result output is available as .Body")
+	fs.Parse(args)
+
+	query, err := sf.query(fs.Args())
+	if err != nil {
+		fs.Usage()
+		return err
+	}
+	if err := sf.loadProxies(); err != nil {
+		return err
+	}
+
+	resultTmpl, err := readTemplate(*format)
+	if err != nil {
+		return err
+	}
+	serpTmpl, err := readTemplate(*serpFormat)
+	if err != nil {
+		return err
+	}
+	var formatter *googlesearch.TemplateFormatter
+	if resultTmpl != "" || serpTmpl != "" {
+		if formatter, err = googlesearch.NewTemplateFormatter(resultTmpl, serpTmpl); err != nil {
+			return err
+		}
+	}
+
+	opts := sf.options()
+	if !sf.noCache {
+		if opts.Cache, err = openCache(); err != nil {
+			return err
+		}
+	}
+	results, metadata, err := googlesearch.SearchWithMetadata(context.Background(), query, opts)
+	if err != nil {
+		return err
+	}
+	if err := appendHistory(query, results); err != nil {
+		fmt.Fprintln(os.Stderr, "googlesearch: recording history:", err)
+	}
+	if formatter == nil {
+		newPrettyPrinter(os.Stdout).print(results, metadata)
+		return nil
+	}
+	return formatter.Format(os.Stdout, googlesearch.FormatData{Query: query, Results: results, Metadata: metadata})
+}
+
+// searchFlags are the flags shared by all commands that run searches.
+type searchFlags struct {
+	n        int
+	lang     string
+	region   string
+	proxy    string
+	timeout  int
+	site     string
+	filetype string
+	before   string
+	after    string
+	exact    string
+	exclude  string
+	noCache  bool
+	cacheTTL time.Duration
+	maxAge   time.Duration
+	noPers   bool
+	preset   string
+
+	proxyFile string
+	pool      *googlesearch.ProxyPool
+}
+
+func (sf *searchFlags) register(fs *flag.FlagSet) {
+	fs.IntVar(&sf.n, "n", 10, "number of results")
+	fs.StringVar(&sf.lang, "lang", "", "interface language (default en, or the preset's)")
+	fs.StringVar(&sf.region, "region", "", "country code to search from")
+	fs.StringVar(&sf.proxy, "proxy", "", "proxy URL")
+	fs.StringVar(&sf.proxyFile, "proxy-file", "", "file with one proxy per line to rotate through")
+	fs.IntVar(&sf.timeout, "timeout", 10, "request timeout in seconds")
+	fs.StringVar(&sf.site, "site", "", "restrict results to a domain (site:)")
+	fs.StringVar(&sf.filetype, "filetype", "", "restrict results to a file type (filetype:)")
+	fs.StringVar(&sf.before, "before", "", "only results published before `YYYY-MM-DD`")
+	fs.StringVar(&sf.after, "after", "", "only results published after `YYYY-MM-DD`")
+	fs.StringVar(&sf.exact, "exact", "", "require an exact phrase")
+	fs.StringVar(&sf.exclude, "exclude", "", "drop results containing a word")
+	fs.BoolVar(&sf.noCache, "no-cache", false, "always fetch fresh results")
+	fs.DurationVar(&sf.cacheTTL, "cache-ttl", time.Hour, "how long cached results are reused")
+	fs.DurationVar(&sf.maxAge, "max-age", 0, "refetch cached results older than this, even within -cache-ttl")
+	fs.StringVar(&sf.preset, "preset", "", "device and locale preset, e.g. us-desktop-chrome or de-mobile-android")
+	fs.BoolVar(&sf.noPers, "no-personalization", false, "ask Google not to personalize results (pws=0)")
+}
+
+// loadProxies reads -proxy-file, if given, into the rotation pool.
+func (sf *searchFlags) loadProxies() error {
+	if sf.proxyFile == "" {
+		return nil
+	}
+	pool, err := googlesearch.LoadProxyFile(sf.proxyFile)
+	if err != nil {
+		return err
+	}
+	sf.pool = pool
+	return nil
+}
+
+// query builds the search query from the positional arguments and the
+// operator flags.
+func (sf *searchFlags) query(args []string) (string, error) {
+	q := googlesearch.NewQuery(strings.Join(args, " ")).
+		Exact(sf.exact).
+		Exclude(sf.exclude).
+		Site(sf.site).
+		FileType(sf.filetype)
+	for _, d := range []struct {
+		value string
+		apply func(time.Time) *googlesearch.QueryBuilder
+	}{{sf.before, q.Before}, {sf.after, q.After}} {
+		if d.value == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", d.value)
+		if err != nil {
+			return "", fmt.Errorf("invalid date %q, want YYYY-MM-DD", d.value)
+		}
+		d.apply(t)
+	}
+	if q.String() == "" {
+		return "", fmt.Errorf("missing query")
+	}
+	return q.String(), nil
+}
+
+func (sf *searchFlags) options() googlesearch.SearchOptions {
+	lang := sf.lang
+	if lang == "" && sf.preset == "" {
+		lang = "en"
+	}
+	opts := googlesearch.SearchOptions{
+		NumResults: sf.n,
+		Lang:       googlesearch.Language(lang),
+		Region:     googlesearch.Region(sf.region),
+		Proxy:      sf.proxy,
+		Timeout:    sf.timeout,
+		CacheTTL:   sf.cacheTTL,
+
+		CacheMaxAge:            sf.maxAge,
+		Preset:                 sf.preset,
+		DisablePersonalization: sf.noPers,
+	}
+	if sf.pool != nil {
+		opts.ProxyProvider = sf.pool
+	}
+	return opts
+}
+
+// readTemplate returns the contents of the named file for "@file" values.
+// Inline templates may use \n and \t escapes.
+func readTemplate(value string) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(value), nil
+	}
+	data, err := os.ReadFile(value[1:])
+	return string(data), err
+}
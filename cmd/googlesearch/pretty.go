@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/1hehaq/googlesearch"
+	"golang.org/x/term"
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiBlue  = "\x1b[34m"
+	ansiGreen = "\x1b[32m"
+)
+
+// prettyPrinter is the default human-readable renderer. Colors are only
+// used on a terminal and when NO_COLOR is unset.
+type prettyPrinter struct {
+	w     io.Writer
+	color bool
+	width int
+}
+
+func newPrettyPrinter(f *os.File) *prettyPrinter {
+	p := &prettyPrinter{w: f, width: 80}
+	if term.IsTerminal(int(f.Fd())) {
+		p.color = os.Getenv("NO_COLOR") == ""
+		if w, _, err := term.GetSize(int(f.Fd())); err == nil && w > 20 {
+			p.width = w
+		}
+	}
+	return p
+}
+
+func (p *prettyPrinter) style(code, s string) string {
+	if !p.color {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+func (p *prettyPrinter) print(results []googlesearch.SearchResult, metadata *googlesearch.SERPMetadata) {
+	if summary := featureSummary(metadata); summary != "" {
+		fmt.Fprintln(p.w, p.style(ansiDim, summary))
+		fmt.Fprintln(p.w)
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(p.w, "No results found.")
+		return
+	}
+	for i, r := range results {
+		prefix := fmt.Sprintf("%2d. ", i+1)
+		indent := strings.Repeat(" ", len(prefix))
+		fmt.Fprintln(p.w, prefix+p.style(ansiBold+ansiBlue, r.Title))
+		url := r.DisplayURL
+		if url == "" {
+			url = r.URL
+		}
+		fmt.Fprintln(p.w, indent+p.style(ansiDim+ansiGreen, url))
+		for _, line := range wrap(r.Description, p.width-len(indent)) {
+			fmt.Fprintln(p.w, indent+line)
+		}
+		fmt.Fprintln(p.w)
+	}
+}
+
+// featureSummary lists the SERP features Google showed besides the organic
+// results, e.g. "Featured snippet · 4 People also ask · Top stories".
+func featureSummary(metadata *googlesearch.SERPMetadata) string {
+	if metadata == nil {
+		return ""
+	}
+	f := metadata.Features
+	var parts []string
+	add := func(ok bool, name string) {
+		if ok {
+			parts = append(parts, name)
+		}
+	}
+	add(f.FeaturedSnippet, "Featured snippet")
+	add(f.AnswerBox, "Answer box")
+	add(f.KnowledgePanel, "Knowledge panel")
+	add(f.LocalPack, "Local pack")
+	add(f.VideoCarousel, "Videos")
+	add(f.ImagePack, "Images")
+	add(f.TopStories, "Top stories")
+	add(f.PeopleAlsoAskCount > 0, fmt.Sprintf("%d People also ask", f.PeopleAlsoAskCount))
+	add(f.AdsCount > 0, fmt.Sprintf("%d ads", f.AdsCount))
+	add(f.RelatedSearchesCount > 0, fmt.Sprintf("%d related searches", f.RelatedSearchesCount))
+	return strings.Join(parts, " · ")
+}
+
+// wrap breaks s into lines of at most width runes at word boundaries.
+func wrap(s string, width int) []string {
+	if width < 20 {
+		width = 20
+	}
+	var lines []string
+	var line strings.Builder
+	for _, word := range strings.Fields(s) {
+		if line.Len() > 0 && utf8.RuneCountInString(line.String())+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteByte(' ')
+		}
+		line.WriteString(word)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
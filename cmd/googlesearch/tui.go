@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/1hehaq/googlesearch"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tui runs the interactive search browser:
+//
+//	googlesearch tui [flags] [query...]
+func tui(args []string) error {
+	fs := flag.NewFlagSet("googlesearch tui", flag.ExitOnError)
+	var sf searchFlags
+	sf.register(fs)
+	fs.Parse(args)
+	if err := sf.loadProxies(); err != nil {
+		return err
+	}
+
+	input := textinput.New()
+	input.Placeholder = "Search Google"
+	input.Prompt = "> "
+	input.SetValue(strings.Join(fs.Args(), " "))
+	input.Focus()
+
+	m := tuiModel{flags: sf, input: input}
+	if input.Value() != "" {
+		m, m.initCmd = m.submit()
+	}
+	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+var (
+	tuiTitle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	tuiSelected = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11"))
+	tuiURL      = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	tuiDim      = lipgloss.NewStyle().Faint(true)
+	tuiError    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+type tuiModel struct {
+	flags searchFlags
+	input textinput.Model
+
+	query    string
+	page     int
+	results  []googlesearch.SearchResult
+	cursor   int
+	loading  bool
+	err      error
+	width    int
+	editing  bool
+	searchID int
+	// initCmd runs the search for a query given on the command line.
+	initCmd tea.Cmd
+}
+
+type resultsMsg struct {
+	id      int
+	results []googlesearch.SearchResult
+	err     error
+}
+
+func (m tuiModel) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, m.initCmd)
+}
+
+// submit starts a search for the input's query from the first page.
+func (m tuiModel) submit() (tuiModel, tea.Cmd) {
+	query, err := m.flags.query([]string{m.input.Value()})
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.query = query
+	m.page = 0
+	m.editing = false
+	m.input.Blur()
+	return m.fetch()
+}
+
+func (m tuiModel) fetch() (tuiModel, tea.Cmd) {
+	m.loading = true
+	m.err = nil
+	m.searchID++
+	id, query := m.searchID, m.query
+	opts := m.flags.options()
+	opts.StartNum = m.page * opts.NumResults
+	return m, func() tea.Msg {
+		results, _, err := googlesearch.SearchWithMetadata(context.Background(), query, opts)
+		return resultsMsg{id: id, results: results, err: err}
+	}
+}
+
+func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+	case resultsMsg:
+		if msg.id != m.searchID {
+			return m, nil
+		}
+		m.loading = false
+		m.results, m.err, m.cursor = msg.results, msg.err, 0
+		return m, nil
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.editing || m.query == "" {
+			switch msg.String() {
+			case "enter":
+				return m.submit()
+			case "esc":
+				if m.query != "" {
+					m.editing = false
+					m.input.Blur()
+				}
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+		switch msg.String() {
+		case "q":
+			return m, tea.Quit
+		case "/":
+			m.editing = true
+			m.input.Focus()
+			return m, textinput.Blink
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.results)-1 {
+				m.cursor++
+			}
+		case "enter", "o":
+			if m.cursor < len(m.results) {
+				if err := openBrowser(m.results[m.cursor].URL); err != nil {
+					m.err = err
+				}
+			}
+		case "n", "right":
+			if !m.loading && len(m.results) > 0 {
+				m.page++
+				return m.fetch()
+			}
+		case "p", "left":
+			if !m.loading && m.page > 0 {
+				m.page--
+				return m.fetch()
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m tuiModel) View() string {
+	var b strings.Builder
+	b.WriteString(m.input.View())
+	b.WriteString("\n\n")
+
+	switch {
+	case m.loading:
+		b.WriteString(tuiDim.Render("Searching…"))
+	case m.err != nil:
+		b.WriteString(tuiError.Render("Error: " + m.err.Error()))
+	case m.query != "" && len(m.results) == 0:
+		b.WriteString("No results found.")
+	default:
+		width := m.width
+		if width <= 0 {
+			width = 80
+		}
+		for i, r := range m.results {
+			marker, title := "  ", tuiTitle.Render(r.Title)
+			if i == m.cursor {
+				marker, title = "> ", tuiSelected.Render(r.Title)
+			}
+			fmt.Fprintf(&b, "%s%d. %s\n", marker, m.page*m.flags.n+i+1, title)
+			fmt.Fprintf(&b, "     %s\n", tuiURL.Render(r.DisplayURL))
+			for _, line := range wrap(r.Description, width-5) {
+				fmt.Fprintf(&b, "     %s\n", tuiDim.Render(line))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	help := "enter search · esc back · ctrl+c quit"
+	if !m.editing && m.query != "" {
+		help = fmt.Sprintf("page %d · ↑/↓ move · enter open · n/p page · / new search · q quit", m.page+1)
+	}
+	b.WriteString(tuiDim.Render(help))
+	return b.String()
+}
+
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,451 @@
+// Command googlesearchd serves the googlesearch package over gRPC, see
+// googlesearchpb/googlesearch.proto for the service definition. With -http
+// it also serves the JSON API of googlesearch.NewAPIHandler, and with
+// -monitor-dir it runs the scheduled queries stored in that directory and,
+// together with -http, serves their rank history at /monitor/export.
+//
+// Every client IP address gets its own token bucket. Buckets idle for
+// longer than it takes them to refill are dropped.
+//
+// All gRPC and REST searches run on one googlesearch.Searcher, so they
+// share its session and pacing; with -session-file the session survives
+// restarts.
+//
+// On SIGINT or SIGTERM the servers stop accepting requests and finish the
+// ones in progress, and the monitor completes its current run, for up to
+// -shutdown-timeout before they are cut off. The sessions are saved last.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/1hehaq/googlesearch"
+	pb "github.com/1hehaq/googlesearch/googlesearchpb"
+	"github.com/1hehaq/googlesearch/monitor"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const maxResultsPerCall = 100
+
+func main() {
+	addr := flag.String("addr", ":50051", "listen address")
+	perMinute := flag.Float64("rate", 10, "searches per minute allowed per client")
+	burst := flag.Int("burst", 3, "burst size per client")
+	proxy := flag.String("proxy", "", "proxy URL for outgoing requests")
+	timeout := flag.Int("timeout", 10, "request timeout in seconds")
+	httpAddr := flag.String("http", "", "listen address for the REST API, disabled if empty")
+	keysFile := flag.String("api-keys", "", "file with one \"key [daily quota]\" per line for the REST API")
+	monitorDir := flag.String("monitor-dir", "", "store directory of scheduled queries to run, disabled if empty")
+	selectorsURL := flag.String("selectors-url", "", "manifest URL of an updated parser selector bundle to fetch at startup")
+	auditFile := flag.String("audit-log", "", "file to append a JSON line to for every outbound request, disabled if empty")
+	sessionFile := flag.String("session-file", "", "file to save the search session to on shutdown and restore it from at startup")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 30*time.Second, "how long to let requests and monitor runs finish on shutdown")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *selectorsURL != "" {
+		bundle, err := googlesearch.UpdateSelectors(context.Background(), googlesearch.SelectorUpdateOptions{URL: *selectorsURL})
+		if err != nil {
+			log.Printf("selector update failed, keeping version %d: %v", bundle.Version, err)
+		} else {
+			log.Printf("using parser selectors version %d", bundle.Version)
+		}
+	}
+
+	base := googlesearch.SearchOptions{Proxy: *proxy, Timeout: *timeout, SessionFile: *sessionFile}
+	if *auditFile != "" {
+		f, err := os.OpenFile(*auditFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		base.AuditLog = &googlesearch.AuditLog{
+			Writer:  f,
+			OnError: func(err error) { log.Printf("audit log: %v", err) },
+		}
+	}
+	searcher, err := googlesearch.NewSearcher(base)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var mon *monitor.Monitor
+	if *monitorDir != "" {
+		store, err := googlesearch.NewFileStore(*monitorDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		monitorBase := base
+		if monitorBase.SessionFile != "" {
+			monitorBase.SessionFile += ".monitor"
+		}
+		if mon, err = monitor.New(store, monitorBase); err != nil {
+			log.Fatal(err)
+		}
+		mon.OnError = func(def monitor.Definition, err error) {
+			log.Printf("scheduled run of %s failed: %v", def.Name, err)
+		}
+		go func() {
+			log.Printf("googlesearchd running scheduled queries from %s", *monitorDir)
+			runMonitor(ctx, mon)
+		}()
+	}
+
+	var httpSrv *http.Server
+	if *httpAddr != "" {
+		keys, err := loadAPIKeys(*keysFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/", googlesearch.NewAPIHandler(googlesearch.APIServerOptions{Searcher: searcher, Keys: keys}))
+		if mon != nil {
+			mux.Handle("/monitor/export", exportHandler(mon))
+		}
+		httpSrv = &http.Server{Addr: *httpAddr, Handler: mux}
+		go func() {
+			log.Printf("googlesearchd REST API listening on %s", *httpAddr)
+			if err := httpSrv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal(err)
+			}
+		}()
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	limits := &clientLimits{limit: rate.Limit(*perMinute / 60), burst: *burst}
+	srv := grpc.NewServer(grpc.StreamInterceptor(limits.intercept))
+	pb.RegisterGoogleSearchServer(srv, &server{searcher: searcher})
+
+	stopped := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		stop()
+		log.Printf("googlesearchd shutting down")
+		shutdown(srv, httpSrv, mon, searcher, *shutdownTimeout)
+		close(stopped)
+	}()
+
+	log.Printf("googlesearchd listening on %s", lis.Addr())
+	if err := srv.Serve(lis); err != nil {
+		log.Fatal(err)
+	}
+	// Serve returns as soon as shutdown begins; wait for it to finish.
+	<-stopped
+}
+
+// maxMonitorBackoff caps the delay before a failed monitor is restarted.
+const maxMonitorBackoff = 5 * time.Minute
+
+// runMonitor runs mon until ctx ends or mon is closed, restarting it with
+// a growing delay when it fails, e.g. on a transient Store error. ctx
+// stops the restarts but not the run in progress: shutdown closes mon, so
+// that run gets the shutdown timeout to finish.
+func runMonitor(ctx context.Context, mon *monitor.Monitor) {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := mon.Start(context.WithoutCancel(ctx))
+		if err == nil || errors.Is(err, googlesearch.ErrClosed) || ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > maxMonitorBackoff {
+			backoff = time.Second
+		}
+		log.Printf("monitor stopped: %v; restarting in %v", err, backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, maxMonitorBackoff)
+	}
+}
+
+// shutdown drains the gRPC and REST servers and the monitor concurrently,
+// forcing them down once timeout has passed, and then closes searcher.
+func shutdown(srv *grpc.Server, httpSrv *http.Server, mon *monitor.Monitor, searcher *googlesearch.Searcher, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		stopped := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			srv.Stop()
+		}
+	}()
+	if httpSrv != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := httpSrv.Shutdown(ctx); err != nil {
+				log.Printf("REST API shutdown: %v", err)
+				httpSrv.Close()
+			}
+		}()
+	}
+	if mon != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := mon.Close(ctx); err != nil {
+				log.Printf("monitor shutdown: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The servers are down, so only searches they abandoned remain.
+	if err := searcher.Close(ctx); err != nil {
+		log.Printf("searcher shutdown: %v", err)
+	}
+	log.Printf("downloaded %d bytes from Google", searcher.BytesDownloaded())
+}
+
+// loadAPIKeys reads API keys with optional daily quotas. Blank lines and
+// lines starting with # are ignored.
+func loadAPIKeys(path string) (map[string]int, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]int)
+	for i, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		quota := 0
+		if len(fields) > 1 {
+			if quota, err = strconv.Atoi(fields[1]); err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid quota %q", path, i+1, fields[1])
+			}
+		}
+		keys[fields[0]] = quota
+	}
+	return keys, nil
+}
+
+// exportHandler serves monitor time series as CSV, or as JSON with
+// format=json. Repeated name and domain parameters filter the series; from
+// and to take RFC 3339 times.
+func exportHandler(mon *monitor.Monitor) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		opts := monitor.ExportOptions{Names: q["name"], Domains: q["domain"]}
+		for param, t := range map[string]*time.Time{"from": &opts.From, "to": &opts.To} {
+			if v := q.Get(param); v != "" {
+				parsed, err := time.Parse(time.RFC3339, v)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("invalid %s: %v", param, err), http.StatusBadRequest)
+					return
+				}
+				*t = parsed
+			}
+		}
+		series, err := mon.Export(r.Context(), opts)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if q.Get("format") == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			monitor.WriteJSON(w, series)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		monitor.WriteCSV(w, series)
+	})
+}
+
+type server struct {
+	pb.UnimplementedGoogleSearchServer
+	searcher *googlesearch.Searcher
+}
+
+func (s *server) Search(req *pb.SearchRequest, stream grpc.ServerStreamingServer[pb.SearchResult]) error {
+	return s.stream(req.GetQuery(), googlesearch.QueryOverrides{
+		NumResults: int(req.GetNumResults()),
+		Lang:       googlesearch.Language(req.GetLang()),
+	}, stream)
+}
+
+func (s *server) SearchAdvanced(req *pb.SearchAdvancedRequest, stream grpc.ServerStreamingServer[pb.SearchResult]) error {
+	overrides := googlesearch.QueryOverrides{
+		NumResults: int(req.GetNumResults()),
+		Lang:       googlesearch.Language(req.GetLang()),
+		Region:     googlesearch.Region(req.GetRegion()),
+		Location:   req.GetLocation(),
+		StartNum:   int(req.GetStart()),
+		Unique:     req.GetUnique(),
+	}
+	if safe := req.GetSafe(); safe != "" {
+		parsed, err := googlesearch.ParseSafeSearch(safe)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		overrides.SafeSearch = parsed
+	}
+	if tbs := req.GetTbs(); tbs != "" {
+		overrides.ExtraParams = map[string][]string{"tbs": {tbs}}
+	}
+	return s.stream(req.GetQuery(), overrides, stream)
+}
+
+func (s *server) stream(query string, overrides googlesearch.QueryOverrides, stream grpc.ServerStreamingServer[pb.SearchResult]) error {
+	if query == "" {
+		return status.Error(codes.InvalidArgument, "query is required")
+	}
+	if overrides.NumResults <= 0 {
+		overrides.NumResults = 10
+	}
+	if overrides.NumResults > maxResultsPerCall {
+		overrides.NumResults = maxResultsPerCall
+	}
+	searcher, err := s.searcher.With(overrides)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	for resp := range searcher.SearchStream(ctx, query) {
+		if resp.Err != nil {
+			return toStatus(resp.Err)
+		}
+		err := stream.Send(&pb.SearchResult{
+			Rank:        int32(resp.Rank),
+			Page:        int32(resp.Page),
+			Url:         resp.Result.URL,
+			DisplayUrl:  resp.Result.DisplayURL,
+			Title:       resp.Result.Title,
+			Description: resp.Result.Description,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return stream.Context().Err()
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, googlesearch.ErrBlocked), errors.Is(err, googlesearch.ErrClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, googlesearch.ErrEmptyQuery), errors.Is(err, googlesearch.ErrQueryTooLong):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// clientLimits keeps one rate limiter per client IP address. The address
+// is used rather than an identifier the client picks, which it could
+// rotate to escape its limit.
+type clientLimits struct {
+	limit rate.Limit
+	burst int
+
+	mu        sync.Mutex
+	limiters  map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+type clientLimiter struct {
+	*rate.Limiter
+	lastSeen time.Time
+}
+
+// minLimiterIdle is the shortest time a limiter is kept after its last
+// use. Limiters are kept at least until their bucket has refilled, so
+// dropping them grants no extra requests.
+const minLimiterIdle = 10 * time.Minute
+
+func (l *clientLimits) intercept(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !l.limiter(clientAddr(ss.Context())).Allow() {
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return handler(srv, ss)
+}
+
+func (l *clientLimits) limiter(addr string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.limiters == nil {
+		l.limiters = make(map[string]*clientLimiter)
+	}
+	idle := l.idle()
+	if now.Sub(l.lastSweep) > idle {
+		for key, lim := range l.limiters {
+			if now.Sub(lim.lastSeen) > idle {
+				delete(l.limiters, key)
+			}
+		}
+		l.lastSweep = now
+	}
+	lim, ok := l.limiters[addr]
+	if !ok {
+		lim = &clientLimiter{Limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[addr] = lim
+	}
+	lim.lastSeen = now
+	return lim.Limiter
+}
+
+// idle returns how long an unused limiter is kept.
+func (l *clientLimits) idle() time.Duration {
+	if l.limit <= 0 {
+		return minLimiterIdle
+	}
+	return max(minLimiterIdle, time.Duration(float64(l.burst)/float64(l.limit)*float64(time.Second)))
+}
+
+// clientAddr returns the IP address of the peer of ctx.
+func clientAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return host
+		}
+		return p.Addr.String()
+	}
+	return ""
+}
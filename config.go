@@ -0,0 +1,360 @@
+package googlesearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Config is the grouped form of SearchOptions. New settings are added to
+// the group they belong to, and Validate checks the whole configuration up
+// front. ConfigFromOptions and SearchOptions convert between the two, so
+// code can move over one call site at a time; Config.SearchOptions also
+// feeds NewSearcher and the other functions that take SearchOptions.
+//
+// The round trip is not exact: SearchOptions holds Network.Timeout and
+// Pacing.Interval in whole seconds, so Validate rejects other durations
+// and SearchOptions rounds them up, and the legacy Safe string is folded
+// into Query.SafeSearch, dropping it if it is invalid.
+type Config struct {
+	Query   QueryConfig
+	Network NetworkConfig
+	Pacing  PacingConfig
+	Parsing ParsingConfig
+	Cache   CacheConfig
+	Output  OutputConfig
+}
+
+// QueryConfig holds what Google is asked for, besides the query itself.
+type QueryConfig struct {
+	Lang   Language
+	Region Region
+	// Location and Coordinates are mutually exclusive, see SearchOptions.
+	Location               string
+	Coordinates            *Coordinates
+	SafeSearch             SafeSearch
+	TimeRange              TimeRange
+	TranslatedResults      bool
+	DisablePersonalization bool
+	ExtraParams            url.Values
+	// Preset fills UserAgent, Lang, Region and Domain where they are not
+	// set, see PresetByName.
+	Preset string
+}
+
+// NetworkConfig holds how requests are sent.
+type NetworkConfig struct {
+	// Proxy and ProxyProvider are mutually exclusive.
+	Proxy         string
+	ProxyProvider ProxyProvider
+	// Timeout bounds every request; zero means no limit. It must be a
+	// whole number of seconds.
+	Timeout            time.Duration
+	InsecureSkipVerify bool
+	UserAgent          string
+	RotateUserAgent    bool
+	UserAgentPages     int
+	Domain             string
+	AlternateDomains   []string
+	Retries            int
+	RetryBackoff       time.Duration
+	BandwidthQuota     int64
+	WarmUp             bool
+	WarmUpQuery        string
+	DisableWarmUp      bool
+	SessionFile        string
+
+	ConsentHandler         ConsentHandler
+	DisableConsentRecovery bool
+	DisableCoalescing      bool
+
+	BlockRegistry *BlockRegistry
+	HARRecorder   *HARRecorder
+	AuditLog      *AuditLog
+}
+
+// PacingConfig holds how fast pages are requested.
+type PacingConfig struct {
+	// Profile and Interval are mutually exclusive; Interval is a fixed
+	// delay between pages and must be a whole number of seconds.
+	Profile  *PacingProfile
+	Interval time.Duration
+	Throttle *AdaptiveThrottle
+}
+
+// ParsingConfig holds how results pages are turned into results.
+type ParsingConfig struct {
+	Extractor           ResultExtractor
+	Normalize           TextNormalization
+	KeepHTML            bool
+	KeepBlockHTML       bool
+	KeepNonWebLinks     bool
+	ExpandMoreResults   bool
+	FeatureSummaryOnly  bool
+	DisableProfileRetry bool
+}
+
+// CacheConfig holds result caching, see SearchOptions.Cache.
+type CacheConfig struct {
+	Cache  Cache
+	TTL    time.Duration
+	MaxAge time.Duration
+}
+
+// OutputConfig holds which results are returned and where they are
+// recorded.
+type OutputConfig struct {
+	NumResults           int
+	StartNum             int
+	AllResults           bool
+	MaxPages             int
+	MinNewResultsPerPage int
+	StopOnEmptyPage      bool
+	StrictCount          bool
+	Unique               bool
+	FuzzyDedup           bool
+	DomainSummary        bool
+	ReRank               func([]SearchResult) []SearchResult
+	ReRankPerPage        bool
+	// Advanced makes SearchWithOptions return SearchResults rather than
+	// plain URLs.
+	Advanced bool
+
+	Hooks   *SearchHooks
+	Labels  Labels
+	Journal *ResultJournal
+	Archive *Archiver
+}
+
+// Validate reports every invalid or contradictory setting.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("google: config: "+format, args...))
+		}
+	}
+	check(c.Output.NumResults >= 0, "Output.NumResults is negative")
+	check(c.Output.StartNum >= 0, "Output.StartNum is negative")
+	check(c.Output.MaxPages >= 0, "Output.MaxPages is negative")
+	check(c.Network.Timeout >= 0, "Network.Timeout is negative")
+	check(c.Network.Retries >= 0, "Network.Retries is negative")
+	check(c.Network.BandwidthQuota >= 0, "Network.BandwidthQuota is negative")
+	check(c.Network.Proxy == "" || c.Network.ProxyProvider == nil, "Network.Proxy and Network.ProxyProvider are both set")
+	check(c.Pacing.Profile == nil || c.Pacing.Interval == 0, "Pacing.Profile and Pacing.Interval are both set")
+	check(c.Pacing.Interval >= 0, "Pacing.Interval is negative")
+	check(c.Network.UserAgentPages >= 0, "Network.UserAgentPages is negative")
+	check(c.Network.Timeout%time.Second == 0, "Network.Timeout %v is not a whole number of seconds", c.Network.Timeout)
+	check(c.Pacing.Interval%time.Second == 0, "Pacing.Interval %v is not a whole number of seconds", c.Pacing.Interval)
+	check(c.Query.SafeSearch >= SafeSearchDefault && c.Query.SafeSearch <= SafeSearchStrict, "unknown Query.SafeSearch %d", int(c.Query.SafeSearch))
+	check(c.Query.Location == "" || c.Query.Coordinates == nil, "Query.Location and Query.Coordinates are both set")
+	check(c.Cache.TTL >= 0 && c.Cache.MaxAge >= 0, "Cache.TTL and Cache.MaxAge must not be negative")
+	if c.Query.Lang != "" {
+		if _, err := ParseLanguage(string(c.Query.Lang)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.Query.Region != "" {
+		if _, err := ParseRegion(string(c.Query.Region)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if _, err := ParseTimeRange(string(c.Query.TimeRange)); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Query.Preset != "" {
+		if _, err := PresetByName(c.Query.Preset); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SearchOptions returns c as flat SearchOptions. A TimeRange Validate
+// accepts, such as "Week" or "any", is stored in its canonical form.
+func (c Config) SearchOptions() SearchOptions {
+	timeRange, err := ParseTimeRange(string(c.Query.TimeRange))
+	if err != nil {
+		timeRange = c.Query.TimeRange
+	}
+	return SearchOptions{
+		Lang:                   c.Query.Lang,
+		Region:                 c.Query.Region,
+		Location:               c.Query.Location,
+		Coordinates:            c.Query.Coordinates,
+		SafeSearch:             c.Query.SafeSearch,
+		TimeRange:              timeRange,
+		TranslatedResults:      c.Query.TranslatedResults,
+		DisablePersonalization: c.Query.DisablePersonalization,
+		ExtraParams:            c.Query.ExtraParams,
+		Preset:                 c.Query.Preset,
+
+		Proxy:                  c.Network.Proxy,
+		ProxyProvider:          c.Network.ProxyProvider,
+		Timeout:                ceilSeconds(c.Network.Timeout),
+		InsecureSkipVerify:     c.Network.InsecureSkipVerify,
+		UserAgent:              c.Network.UserAgent,
+		RotateUserAgent:        c.Network.RotateUserAgent,
+		UserAgentPages:         c.Network.UserAgentPages,
+		Domain:                 c.Network.Domain,
+		AlternateDomains:       c.Network.AlternateDomains,
+		Retries:                c.Network.Retries,
+		RetryBackoff:           c.Network.RetryBackoff,
+		BandwidthQuota:         c.Network.BandwidthQuota,
+		WarmUp:                 c.Network.WarmUp,
+		WarmUpQuery:            c.Network.WarmUpQuery,
+		DisableWarmUp:          c.Network.DisableWarmUp,
+		SessionFile:            c.Network.SessionFile,
+		ConsentHandler:         c.Network.ConsentHandler,
+		DisableConsentRecovery: c.Network.DisableConsentRecovery,
+		DisableCoalescing:      c.Network.DisableCoalescing,
+		BlockRegistry:          c.Network.BlockRegistry,
+		HARRecorder:            c.Network.HARRecorder,
+		AuditLog:               c.Network.AuditLog,
+
+		Pacing:        c.Pacing.Profile,
+		SleepInterval: ceilSeconds(c.Pacing.Interval),
+		Throttle:      c.Pacing.Throttle,
+
+		Extractor:           c.Parsing.Extractor,
+		Normalize:           c.Parsing.Normalize,
+		KeepHTML:            c.Parsing.KeepHTML,
+		KeepBlockHTML:       c.Parsing.KeepBlockHTML,
+		KeepNonWebLinks:     c.Parsing.KeepNonWebLinks,
+		ExpandMoreResults:   c.Parsing.ExpandMoreResults,
+		FeatureSummaryOnly:  c.Parsing.FeatureSummaryOnly,
+		DisableProfileRetry: c.Parsing.DisableProfileRetry,
+
+		Cache:       c.Cache.Cache,
+		CacheTTL:    c.Cache.TTL,
+		CacheMaxAge: c.Cache.MaxAge,
+
+		NumResults:           c.Output.NumResults,
+		StartNum:             c.Output.StartNum,
+		AllResults:           c.Output.AllResults,
+		MaxPages:             c.Output.MaxPages,
+		MinNewResultsPerPage: c.Output.MinNewResultsPerPage,
+		StopOnEmptyPage:      c.Output.StopOnEmptyPage,
+		StrictCount:          c.Output.StrictCount,
+		Unique:               c.Output.Unique,
+		FuzzyDedup:           c.Output.FuzzyDedup,
+		DomainSummary:        c.Output.DomainSummary,
+		Advanced:             c.Output.Advanced,
+		ReRank:               c.Output.ReRank,
+		ReRankPerPage:        c.Output.ReRankPerPage,
+		Hooks:                c.Output.Hooks,
+		Labels:               c.Output.Labels,
+		Journal:              c.Output.Journal,
+		Archive:              c.Output.Archive,
+	}
+}
+
+// ConfigFromOptions groups opts into a Config. The legacy Safe string is
+// folded into SafeSearch.
+func ConfigFromOptions(opts SearchOptions) Config {
+	safe := opts.SafeSearch
+	if safe == SafeSearchDefault && opts.Safe != "" {
+		safe, _ = ParseSafeSearch(opts.Safe)
+	}
+	return Config{
+		Query: QueryConfig{
+			Lang:                   opts.Lang,
+			Region:                 opts.Region,
+			Location:               opts.Location,
+			Coordinates:            opts.Coordinates,
+			SafeSearch:             safe,
+			TimeRange:              opts.TimeRange,
+			TranslatedResults:      opts.TranslatedResults,
+			DisablePersonalization: opts.DisablePersonalization,
+			ExtraParams:            opts.ExtraParams,
+			Preset:                 opts.Preset,
+		},
+		Network: NetworkConfig{
+			Proxy:                  opts.Proxy,
+			ProxyProvider:          opts.ProxyProvider,
+			Timeout:                time.Duration(opts.Timeout) * time.Second,
+			InsecureSkipVerify:     opts.InsecureSkipVerify,
+			UserAgent:              opts.UserAgent,
+			RotateUserAgent:        opts.RotateUserAgent,
+			UserAgentPages:         opts.UserAgentPages,
+			Domain:                 opts.Domain,
+			AlternateDomains:       opts.AlternateDomains,
+			Retries:                opts.Retries,
+			RetryBackoff:           opts.RetryBackoff,
+			BandwidthQuota:         opts.BandwidthQuota,
+			WarmUp:                 opts.WarmUp,
+			WarmUpQuery:            opts.WarmUpQuery,
+			DisableWarmUp:          opts.DisableWarmUp,
+			SessionFile:            opts.SessionFile,
+			ConsentHandler:         opts.ConsentHandler,
+			DisableConsentRecovery: opts.DisableConsentRecovery,
+			DisableCoalescing:      opts.DisableCoalescing,
+			BlockRegistry:          opts.BlockRegistry,
+			HARRecorder:            opts.HARRecorder,
+			AuditLog:               opts.AuditLog,
+		},
+		Pacing: PacingConfig{
+			Profile:  opts.Pacing,
+			Interval: time.Duration(opts.SleepInterval) * time.Second,
+			Throttle: opts.Throttle,
+		},
+		Parsing: ParsingConfig{
+			Extractor:           opts.Extractor,
+			Normalize:           opts.Normalize,
+			KeepHTML:            opts.KeepHTML,
+			KeepBlockHTML:       opts.KeepBlockHTML,
+			KeepNonWebLinks:     opts.KeepNonWebLinks,
+			ExpandMoreResults:   opts.ExpandMoreResults,
+			FeatureSummaryOnly:  opts.FeatureSummaryOnly,
+			DisableProfileRetry: opts.DisableProfileRetry,
+		},
+		Cache: CacheConfig{
+			Cache:  opts.Cache,
+			TTL:    opts.CacheTTL,
+			MaxAge: opts.CacheMaxAge,
+		},
+		Output: OutputConfig{
+			NumResults:           opts.NumResults,
+			StartNum:             opts.StartNum,
+			AllResults:           opts.AllResults,
+			MaxPages:             opts.MaxPages,
+			MinNewResultsPerPage: opts.MinNewResultsPerPage,
+			StopOnEmptyPage:      opts.StopOnEmptyPage,
+			StrictCount:          opts.StrictCount,
+			Unique:               opts.Unique,
+			FuzzyDedup:           opts.FuzzyDedup,
+			DomainSummary:        opts.DomainSummary,
+			Advanced:             opts.Advanced,
+			ReRank:               opts.ReRank,
+			ReRankPerPage:        opts.ReRankPerPage,
+			Hooks:                opts.Hooks,
+			Labels:               opts.Labels,
+			Journal:              opts.Journal,
+			Archive:              opts.Archive,
+		},
+	}
+}
+
+// ceilSeconds converts d to the whole seconds of the flat options, never
+// rounding a positive duration down to zero.
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// SearchAll validates cfg and returns up to cfg.Output.NumResults results
+// for query (10 if unset) together with the SERP metadata. It is the
+// Config counterpart of SearchWithMetadata.
+func SearchAll(ctx context.Context, query string, cfg Config) ([]SearchResult, *SERPMetadata, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if cfg.Output.NumResults == 0 {
+		cfg.Output.NumResults = 10
+	}
+	return search(ctx, query, cfg.SearchOptions())
+}
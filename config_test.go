@@ -0,0 +1,64 @@
+package googlesearch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		valid  bool
+	}{
+		{name: "defaults", modify: func(*Config) {}, valid: true},
+		{name: "whole second timeout", modify: func(c *Config) { c.Network.Timeout = 3 * time.Second }, valid: true},
+		{name: "sub-second timeout", modify: func(c *Config) { c.Network.Timeout = 1500 * time.Millisecond }},
+		{name: "sub-second interval", modify: func(c *Config) { c.Pacing.Interval = 500 * time.Millisecond }},
+		{name: "unknown safe search", modify: func(c *Config) { c.Query.SafeSearch = SafeSearchStrict + 1 }},
+		{name: "known time range", modify: func(c *Config) { c.Query.TimeRange = TimeRangeDay }, valid: true},
+		{name: "unknown time range", modify: func(c *Config) { c.Query.TimeRange = "fortnight" }},
+		{name: "any time range", modify: func(c *Config) { c.Query.TimeRange = "any" }, valid: true},
+		{name: "mixed-case time range", modify: func(c *Config) { c.Query.TimeRange = "Week" }, valid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			tt.modify(&c)
+			if err := c.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestConfigNormalizesTimeRange(t *testing.T) {
+	for input, want := range map[TimeRange]string{
+		"any":  "",
+		"Week": "qdr:w",
+		"D":    "qdr:d",
+		"year": "qdr:y",
+	} {
+		var c Config
+		c.Query.TimeRange = input
+		opts := c.SearchOptions()
+		if got := opts.TimeRange.tbs(); got != want {
+			t.Errorf("TimeRange %q sends tbs %q, want %q", input, got, want)
+		}
+		prepared, err := prepareOptions(SearchOptions{TimeRange: input})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := prepared.TimeRange.tbs(); got != want {
+			t.Errorf("prepared TimeRange %q sends tbs %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	opts := SearchOptions{NumResults: 20, Timeout: 5, Advanced: true, SafeSearch: SafeSearchStrict}
+	got := ConfigFromOptions(opts).SearchOptions()
+	if got.NumResults != opts.NumResults || got.Timeout != opts.Timeout || !got.Advanced || got.SafeSearch != opts.SafeSearch {
+		t.Errorf("round trip of %+v gave %+v", opts, got)
+	}
+}
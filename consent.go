@@ -0,0 +1,142 @@
+package googlesearch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ErrConsent is returned when Google redirected to its cookie consent page
+// and the consent exchange could not be completed.
+var ErrConsent = errors.New("google: stuck on consent page")
+
+// ConsentHandler completes Google's cookie consent exchange. It receives
+// the consent page the search was redirected to and returns the cookies to
+// send with subsequent requests.
+type ConsentHandler func(ctx context.Context, client *http.Client, page *http.Response, body []byte) ([]*http.Cookie, error)
+
+// isConsentPage reports whether resp ended up on consent.google.*.
+func isConsentPage(resp *http.Response) bool {
+	return resp.Request != nil && strings.HasPrefix(resp.Request.URL.Hostname(), "consent.google.")
+}
+
+// DefaultConsentHandler submits the consent form, preferring the "Reject
+// all" choice, and collects the cookies Google sets in response.
+func DefaultConsentHandler(ctx context.Context, client *http.Client, page *http.Response, body []byte) ([]*http.Cookie, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	var form *goquery.Selection
+	doc.Find("form").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		if form == nil {
+			form = s
+		}
+		label := strings.ToLower(s.Find("button, input[type=submit]").Text() + s.Find("input[type=submit]").AttrOr("value", ""))
+		if strings.Contains(label, "reject") {
+			form = s
+			return false
+		}
+		return true
+	})
+	if form == nil {
+		return nil, fmt.Errorf("%w: no consent form found", ErrConsent)
+	}
+
+	action, err := page.Request.URL.Parse(form.AttrOr("action", ""))
+	if err != nil {
+		return nil, err
+	}
+	values := url.Values{}
+	form.Find("input[name]").Each(func(i int, s *goquery.Selection) {
+		if typ := strings.ToLower(s.AttrOr("type", "hidden")); typ == "hidden" || typ == "submit" {
+			values.Add(s.AttrOr("name", ""), s.AttrOr("value", ""))
+		}
+	})
+	method := strings.ToUpper(form.AttrOr("method", "POST"))
+
+	var req *http.Request
+	if method == "GET" {
+		action.RawQuery = values.Encode()
+		req, err = http.NewRequestWithContext(ctx, "GET", action.String(), nil)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, "POST", action.String(), strings.NewReader(values.Encode()))
+		if req != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", page.Request.Header.Get("User-Agent"))
+	req.Header.Set("Referer", page.Request.URL.String())
+
+	// Collect the cookies set along the redirect chain in a private jar.
+	jar, _ := cookiejar.New(nil)
+	c := *client
+	c.Jar = jar
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	// The cookies are read back for the Google domain being searched,
+	// e.g. www.google.de with Domain "google.de".
+	cookies := jar.Cookies(&url.URL{Scheme: "https", Host: "www." + searchDomain(ctx), Path: "/"})
+	if len(cookies) == 0 {
+		return nil, fmt.Errorf("%w: consent form returned no cookies", ErrConsent)
+	}
+	return cookies, nil
+}
+
+// completeConsent runs the configured ConsentHandler and remembers the
+// returned cookies for the rest of the session.
+func (s *session) completeConsent(ctx context.Context, resp *http.Response, body []byte) error {
+	handler := s.opts.ConsentHandler
+	if handler == nil {
+		handler = DefaultConsentHandler
+	}
+	cookies, err := handler(ctx, s.httpClient(), resp, body)
+	if err != nil {
+		if !errors.Is(err, ErrConsent) {
+			err = fmt.Errorf("%w: %v", ErrConsent, err)
+		}
+		return err
+	}
+	s.mu.Lock()
+	s.consentCookies = cookies
+	s.mu.Unlock()
+	return nil
+}
+
+// addConsentCookies sets the consent cookies on req: those obtained by
+// completeConsent if any, else the preset ones that usually skip the
+// consent page.
+func (s *session) addConsentCookies(req *http.Request) {
+	s.mu.Lock()
+	cookies := s.consentCookies
+	s.mu.Unlock()
+
+	have := make(map[string]bool)
+	for _, c := range cookies {
+		req.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+		have[c.Name] = true
+	}
+	if !have["CONSENT"] {
+		req.AddCookie(&http.Cookie{Name: "CONSENT", Value: "PENDING+987"})
+	}
+	if !have["SOCS"] {
+		req.AddCookie(&http.Cookie{Name: "SOCS", Value: "CAESHAgBEhIaAB"})
+	}
+}
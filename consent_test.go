@@ -0,0 +1,41 @@
+package googlesearch
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDefaultConsentHandlerReadsSearchDomain(t *testing.T) {
+	google := newFakeGoogleFunc(t, func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "SOCS", Value: "rejected", Domain: "google.de", Path: "/"})
+	})
+	proxy := newFakeProxy(t, google)
+	proxyURL, _ := url.Parse(proxy.URL)
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	page := &http.Response{Request: httptest.NewRequest(http.MethodGet, "https://consent.google.de/ml?continue=https://www.google.de/search", nil)}
+	body := []byte(`<form action="https://consent.google.de/save" method="POST"><input type="hidden" name="set_eom" value="true"><button>Reject all</button></form>`)
+
+	de := withDomain(context.Background(), "google.de")
+	cookies, err := DefaultConsentHandler(de, client, page, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cookies) != 1 || cookies[0].Name != "SOCS" {
+		t.Errorf("got cookies %v, want SOCS", cookies)
+	}
+
+	// The same cookies do not apply to google.com.
+	_, err = DefaultConsentHandler(context.Background(), client, page, body)
+	if !errors.Is(err, ErrConsent) || !strings.Contains(err.Error(), "no cookies") {
+		t.Errorf("google.com: got %v, want ErrConsent for missing cookies", err)
+	}
+}
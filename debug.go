@@ -0,0 +1,73 @@
+package googlesearch
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// debugBodyLimit is how much of the response body DebugInfo keeps.
+const debugBodyLimit = 4096
+
+// debugSelectors are counted on failed pages, besides the current
+// SelectorBundle, to show which parts of the expected layout were present.
+var debugSelectors = []string{
+	"a[href^='/url?q=']",
+	"div.g",
+	"div#search",
+	"form#captcha-form",
+	"form[action*='consent']",
+}
+
+// DebugInfo describes the response behind a failed or empty page. Retrieve
+// it from an error with errors.As and a *DebugError target.
+type DebugInfo struct {
+	URL        string
+	StatusCode int
+	Header     http.Header
+	// Body holds the first bytes of the response body.
+	Body         []byte
+	SelectorHits map[string]int
+}
+
+// DebugError wraps an error with the DebugInfo of the response that caused
+// it. errors.Is keeps matching the wrapped error, e.g. ErrBlocked.
+type DebugError struct {
+	Err   error
+	Debug *DebugInfo
+}
+
+func (e *DebugError) Error() string {
+	if e.Debug == nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s (url=%s, status=%d)", e.Err, e.Debug.URL, e.Debug.StatusCode)
+}
+
+func (e *DebugError) Unwrap() error {
+	return e.Err
+}
+
+func newDebugError(err error, resp *http.Response, body []byte, doc *goquery.Document) error {
+	info := &DebugInfo{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+	}
+	if resp.Request != nil {
+		info.URL = resp.Request.URL.String()
+	}
+	if len(body) > debugBodyLimit {
+		body = body[:debugBodyLimit]
+	}
+	info.Body = append([]byte(nil), body...)
+	if doc != nil {
+		bundle := CurrentSelectors()
+		selectors := append([]string{bundle.Result, bundle.Title, bundle.Description}, debugSelectors...)
+		info.SelectorHits = make(map[string]int, len(selectors))
+		for _, selector := range selectors {
+			info.SelectorHits[selector] = doc.Find(selector).Length()
+		}
+	}
+	return &DebugError{Err: err, Debug: info}
+}
@@ -0,0 +1,59 @@
+package googlesearch
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// fuzzyKey identifies near-duplicate results that deep pagination tends to
+// repeat under slightly different URLs: the normalized title combined with
+// the registrable domain (eTLD+1). It returns "" when the result has no
+// usable title.
+func fuzzyKey(result SearchResult) string {
+	title := normalizeTitle(result.Title)
+	if title == "" {
+		return ""
+	}
+	domain := registrableDomain(result.URL)
+	if domain == "" {
+		return ""
+	}
+	return domain + "|" + title
+}
+
+// registrableDomain returns the eTLD+1 of rawURL's host, the host itself
+// when it has none (IP addresses, localhost), or "" for unparsable URLs.
+func registrableDomain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
+
+// normalizeTitle lowercases title, drops punctuation and a trailing
+// " - Site Name" or " | Site Name" suffix, and collapses whitespace.
+func normalizeTitle(title string) string {
+	title = strings.ToLower(normalizeSpace(title))
+	for _, sep := range []string{" | ", " - ", " – ", " — "} {
+		if i := strings.LastIndex(title, sep); i > 0 {
+			title = title[:i]
+		}
+	}
+	title = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) {
+			return r
+		}
+		return ' '
+	}, title)
+	title = strings.TrimSuffix(normalizeSpace(title), " ...")
+	return normalizeSpace(title)
+}
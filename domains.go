@@ -0,0 +1,60 @@
+package googlesearch
+
+import (
+	"context"
+	"strings"
+)
+
+// DefaultAlternateDomains is a suggested value for
+// SearchOptions.AlternateDomains.
+var DefaultAlternateDomains = []string{
+	"google.ca", "google.co.uk", "google.com.au", "google.nl", "google.de", "google.ie",
+}
+
+type domainContextKey struct{}
+
+// withDomain makes requests made with ctx go to the given Google domain
+// instead of google.com.
+func withDomain(ctx context.Context, domain string) context.Context {
+	return context.WithValue(ctx, domainContextKey{}, domain)
+}
+
+// searchDomain returns the Google domain to use for ctx.
+func searchDomain(ctx context.Context) string {
+	if domain, ok := ctx.Value(domainContextKey{}).(string); ok && domain != "" {
+		return domain
+	}
+	return "google.com"
+}
+
+// domainContext applies opts.Domain to ctx.
+func (o SearchOptions) domainContext(ctx context.Context) context.Context {
+	if o.Domain == "" {
+		return ctx
+	}
+	return withDomain(ctx, o.Domain)
+}
+
+// normalizeGoogleDomain accepts "google.ca", "www.google.ca" or a URL.
+func normalizeGoogleDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if i := strings.Index(domain, "://"); i >= 0 {
+		domain = domain[i+3:]
+	}
+	domain = strings.TrimSuffix(strings.SplitN(domain, "/", 2)[0], ".")
+	return strings.TrimPrefix(domain, "www.")
+}
+
+// domainCountry returns the gl value matching a country domain such as
+// google.co.uk, or "" for google.com. Like ParseRegion, it returns "gb"
+// rather than "uk" for the United Kingdom.
+func domainCountry(domain string) string {
+	i := strings.LastIndex(domain, ".")
+	if i < 0 || domain[i+1:] == "com" {
+		return ""
+	}
+	if domain[i+1:] == "uk" {
+		return "gb"
+	}
+	return domain[i+1:]
+}
@@ -0,0 +1,38 @@
+package googlesearch
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestDomainCountry(t *testing.T) {
+	for domain, want := range map[string]string{
+		"google.com":    "",
+		"google.co.uk":  "gb",
+		"google.com.au": "au",
+		"google.de":     "de",
+	} {
+		if got := domainCountry(domain); got != want {
+			t.Errorf("domainCountry(%q) = %q, want %q", domain, got, want)
+		}
+	}
+}
+
+func TestResolveGoogleURL(t *testing.T) {
+	page, _ := url.Parse("https://www.google.co.uk/search?q=golang")
+	tests := []struct {
+		base *url.URL
+		href string
+		want string
+	}{
+		{nil, "/maps/place/x", "https://www.google.com/maps/place/x"},
+		{page, "/maps/place/x", "https://www.google.co.uk/maps/place/x"},
+		{page, "/url?q=https://example.com/", "https://example.com/"},
+		{page, "https://example.org/", "https://example.org/"},
+	}
+	for _, tt := range tests {
+		if got := resolveGoogleURL(tt.base, tt.href); got != tt.want {
+			t.Errorf("resolveGoogleURL(%v, %q) = %q, want %q", tt.base, tt.href, got, tt.want)
+		}
+	}
+}
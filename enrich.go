@@ -0,0 +1,133 @@
+package googlesearch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RedirectHop is one response on the way from a result URL to its final
+// destination.
+type RedirectHop struct {
+	URL        string
+	StatusCode int
+}
+
+// EnrichedResult is a SearchResult with the outcome of visiting its URL.
+type EnrichedResult struct {
+	SearchResult
+	// Reachable reports whether the final response had a 2xx status.
+	Reachable  bool
+	StatusCode int
+	FinalURL   string
+	// RedirectChain lists every response received, starting with the
+	// result URL itself and ending with the final one.
+	RedirectChain []RedirectHop
+	// Err describes why the URL could not be resolved, if it could not.
+	Err string
+}
+
+// EnrichOptions configures Enrich.
+type EnrichOptions struct {
+	// Concurrency is the number of URLs checked in parallel; default 4.
+	Concurrency int
+	// Timeout bounds each URL including all redirects; default 15s.
+	Timeout time.Duration
+	// MaxRedirects defaults to 10.
+	MaxRedirects int
+	UserAgent    string
+	// Client is used for the requests; its CheckRedirect is overridden.
+	Client *http.Client
+}
+
+// Enrich visits the URL of every result and records reachability and the
+// full redirect chain, hop by hop, as needed for affiliate and cloaking
+// investigations. Per-result failures are reported in Err; the returned
+// error is only set when ctx ends.
+func Enrich(ctx context.Context, results []SearchResult, opts EnrichOptions) ([]EnrichedResult, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 15 * time.Second
+	}
+	if opts.MaxRedirects <= 0 {
+		opts.MaxRedirects = 10
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = getRandomUserAgent()
+	}
+	client := &http.Client{}
+	if opts.Client != nil {
+		*client = *opts.Client
+	}
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	enriched := make([]EnrichedResult, len(results))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i, result := range results {
+		enriched[i].SearchResult = result
+		wg.Add(1)
+		go func(e *EnrichedResult) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				e.Err = ctx.Err().Error()
+				return
+			}
+			resolveRedirects(ctx, client, e, opts)
+		}(&enriched[i])
+	}
+	wg.Wait()
+	return enriched, ctx.Err()
+}
+
+func resolveRedirects(ctx context.Context, client *http.Client, e *EnrichedResult, opts EnrichOptions) {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	target := e.URL
+	for hops := 0; ; hops++ {
+		if hops > opts.MaxRedirects {
+			e.Err = fmt.Sprintf("stopped after %d redirects", opts.MaxRedirects)
+			return
+		}
+		req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+		if err != nil {
+			e.Err = err.Error()
+			return
+		}
+		req.Header.Set("User-Agent", opts.UserAgent)
+		resp, err := client.Do(req)
+		if err != nil {
+			e.Err = err.Error()
+			return
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+
+		e.RedirectChain = append(e.RedirectChain, RedirectHop{URL: target, StatusCode: resp.StatusCode})
+		e.StatusCode = resp.StatusCode
+		e.FinalURL = target
+
+		location := resp.Header.Get("Location")
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+			e.Reachable = resp.StatusCode >= 200 && resp.StatusCode < 300
+			return
+		}
+		next, err := req.URL.Parse(location)
+		if err != nil {
+			e.Err = fmt.Sprintf("invalid Location %q: %v", location, err)
+			return
+		}
+		target = next.String()
+	}
+}
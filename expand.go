@@ -0,0 +1,82 @@
+package googlesearch
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// extractMoreResultsTerms returns the search terms behind "More results
+// from example.com" links, which Google shows when it collapses a domain
+// to a single result.
+func extractMoreResultsTerms(doc *goquery.Document) []string {
+	var terms []string
+	seen := map[string]bool{}
+	doc.Find("a[href^='/search?']").Each(func(i int, a *goquery.Selection) {
+		u, err := url.Parse(a.AttrOr("href", ""))
+		if err != nil {
+			return
+		}
+		q := u.Query()
+		term := q.Get("q")
+		if site := q.Get("as_sitesearch"); site != "" {
+			term += " site:" + site
+		} else if !strings.Contains(term, "site:") {
+			return
+		}
+		if !strings.Contains(strings.ToLower(normalizeSpace(a.Text())), "more results from") {
+			return
+		}
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	})
+	return terms
+}
+
+// expandMoreResults fetches the first page of every "More results from"
+// link on page and delivers results not seen before, flagged with
+// FromExpansion. It returns how many results were delivered.
+func expandMoreResults(ctx context.Context, sess *session, page *serpPage, pageNum int, remaining int, seen map[string]bool, fn func(SearchResponse) error) (int, error) {
+	delivered := 0
+	labels := searchLabels(ctx, sess.opts)
+	for _, term := range page.moreResultsTerms {
+		if delivered >= remaining {
+			break
+		}
+		if err := sess.prepare(ctx); err != nil {
+			return delivered, err
+		}
+		expansion, err := sess.fetchPage(withPageNum(ctx, 1), term, 0)
+		if err != nil {
+			return delivered, err
+		}
+
+		for i, result := range expansion.results {
+			if delivered >= remaining {
+				break
+			}
+			if seen[result.URL] {
+				continue
+			}
+			seen[result.URL] = true
+
+			result.FromExpansion = true
+			resp := SearchResponse{
+				Result:      result,
+				Page:        pageNum,
+				IndexOnPage: i + 1,
+				Labels:      labels,
+			}
+			sess.opts.Hooks.result(resp)
+			if err := fn(resp); err != nil {
+				return delivered, err
+			}
+			delivered++
+		}
+	}
+	return delivered, nil
+}
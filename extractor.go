@@ -0,0 +1,32 @@
+package googlesearch
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// ResultExtractor turns a parsed results page into organic results and a
+// feature summary. Set SearchOptions.Extractor to use custom extraction
+// logic, for example for an experimental layout, while keeping the
+// fetching, pagination and anti-blocking machinery. Answer boxes and other
+// metadata are still extracted by the package.
+//
+// A page for which Extract returns no results is treated like any other
+// empty page, including the retry with the alternate fetch profile.
+type ResultExtractor interface {
+	Extract(doc *html.Node) ([]SearchResult, SERPFeatures, error)
+}
+
+// ExtractorFunc adapts a function to the ResultExtractor interface.
+type ExtractorFunc func(doc *html.Node) ([]SearchResult, SERPFeatures, error)
+
+func (f ExtractorFunc) Extract(doc *html.Node) ([]SearchResult, SERPFeatures, error) {
+	return f(doc)
+}
+
+// DefaultExtractor is the built-in extraction, for custom extractors that
+// only fix up or extend its output.
+var DefaultExtractor ResultExtractor = ExtractorFunc(func(doc *html.Node) ([]SearchResult, SERPFeatures, error) {
+	d := goquery.NewDocumentFromNode(doc)
+	return extractResults(d, SearchOptions{}), extractFeatures(d), nil
+})
@@ -0,0 +1,533 @@
+package googlesearch
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+type SportsGame struct {
+	League    string
+	HomeTeam  string
+	AwayTeam  string
+	HomeScore string
+	AwayScore string
+	// State is the game status as displayed, e.g. "Final", "Live" or a
+	// kick-off time for upcoming games.
+	State string
+}
+
+func extractSports(doc *goquery.Document) []SportsGame {
+	var games []SportsGame
+
+	doc.Find("div.imso_mh__ma-sc-cont").Each(func(i int, s *goquery.Selection) {
+		teams := s.Find("div.imso_mh__tm-nm")
+		if teams.Length() < 2 {
+			return
+		}
+		games = append(games, SportsGame{
+			League:    normalizeSpace(s.Closest("div.imso-ani").Find("span.imso-hide-overflow").First().Text()),
+			HomeTeam:  normalizeSpace(teams.Eq(0).Text()),
+			AwayTeam:  normalizeSpace(teams.Eq(1).Text()),
+			HomeScore: normalizeSpace(s.Find("div.imso_mh__l-tm-sc").First().Text()),
+			AwayScore: normalizeSpace(s.Find("div.imso_mh__r-tm-sc").First().Text()),
+			State:     normalizeSpace(s.Find("div.imso_mh__ft-mtch, span.imso_mh__ft-mtch, div.imso_mh__stts-l").First().Text()),
+		})
+	})
+
+	doc.Find("div.imspo_mt__mit").Each(func(i int, s *goquery.Selection) {
+		teams := s.Find("div.imspo_mt__tt-w")
+		scores := s.Find("div.imspo_mt__t-sc")
+		if teams.Length() < 2 {
+			return
+		}
+		game := SportsGame{
+			League:   normalizeSpace(s.Closest("div.imspo_mt__mt-t").Find("div.imspo_mt__lg-st-co").First().Text()),
+			HomeTeam: normalizeSpace(teams.Eq(0).Text()),
+			AwayTeam: normalizeSpace(teams.Eq(1).Text()),
+			State:    normalizeSpace(s.Find("div.imspo_mt__game-status, div.imspo_mt__ndl-p").First().Text()),
+		}
+		if scores.Length() >= 2 {
+			game.HomeScore = normalizeSpace(scores.Eq(0).Text())
+			game.AwayScore = normalizeSpace(scores.Eq(1).Text())
+		}
+		games = append(games, game)
+	})
+
+	return games
+}
+
+type FlightsBox struct {
+	Route   string
+	Options []FlightOption
+}
+
+type FlightOption struct {
+	Airline  string
+	Stops    string
+	Duration string
+	Price    string
+	URL      string
+}
+
+var (
+	durationPattern = regexp.MustCompile(`(?i)^\d+\s*h(r|rs)?(\s*\d+\s*m(in)?)?$|^\d+\s*m(ins?)?$`)
+	pricePattern    = regexp.MustCompile(`[$€£¥₹]|\b[A-Z]{3}\s?\d|\d\s?[A-Z]{3}\b`)
+	stopsPattern    = regexp.MustCompile(`(?i)nonstop|non-stop|direct|\d+\+?\s*stops?`)
+)
+
+func extractFlights(doc *goquery.Document) *FlightsBox {
+	var box *FlightsBox
+	doc.Find("a[href*='/travel/flights']").Each(func(i int, a *goquery.Selection) {
+		parts := leafTexts(a)
+		if len(parts) < 2 {
+			return
+		}
+
+		var option FlightOption
+		for _, part := range parts {
+			switch {
+			case option.Duration == "" && durationPattern.MatchString(part):
+				option.Duration = part
+			case option.Stops == "" && stopsPattern.MatchString(part):
+				option.Stops = part
+			case option.Price == "" && pricePattern.MatchString(part):
+				option.Price = part
+			case option.Airline == "":
+				option.Airline = part
+			}
+		}
+		if option.Duration == "" && option.Price == "" {
+			return
+		}
+		option.URL = resolveGoogleURL(doc.Url, a.AttrOr("href", ""))
+
+		if box == nil {
+			box = &FlightsBox{
+				Route: normalizeSpace(a.Closest("div[data-hveid]").Find("[role='heading']").First().Text()),
+			}
+		}
+		box.Options = append(box.Options, option)
+	})
+	return box
+}
+
+// leafTexts returns the normalized text of every element below s that has
+// no element children, skipping empty ones. Google's widgets render each
+// field in its own leaf node, so this survives class name churn.
+func leafTexts(s *goquery.Selection) []string {
+	var texts []string
+	s.Find("*").Each(func(i int, el *goquery.Selection) {
+		if el.Children().Length() > 0 {
+			return
+		}
+		if text := normalizeSpace(el.Text()); text != "" {
+			texts = append(texts, text)
+		}
+	})
+	return texts
+}
+
+// resolveGoogleURL turns relative and /url?q= redirect links into absolute
+// target URLs. Relative links are resolved against base, the URL of the
+// results page, or against https://www.google.com when base is nil.
+func resolveGoogleURL(base *url.URL, href string) string {
+	if href == "" {
+		return ""
+	}
+	if strings.HasPrefix(href, "/url?") {
+		if u, err := url.Parse(href); err == nil {
+			if target := u.Query().Get("q"); target != "" {
+				return target
+			}
+			if target := u.Query().Get("url"); target != "" {
+				return target
+			}
+		}
+	}
+	if strings.HasPrefix(href, "/") {
+		if base == nil || base.Host == "" {
+			return "https://www.google.com" + href
+		}
+		return "https://" + base.Host + href
+	}
+	return href
+}
+
+type Event struct {
+	Name  string
+	Date  string
+	Venue string
+	URL   string
+}
+
+func extractEvents(doc *goquery.Document) []Event {
+	var events []Event
+	doc.Find("div.PaEvOc").Each(func(i int, s *goquery.Selection) {
+		event := Event{
+			Name:  normalizeSpace(s.Find("div.YOGjf").First().Text()),
+			Date:  normalizeSpace(s.Find("div.t3gkGd").First().Text()),
+			Venue: normalizeSpace(s.Find("div.zvDXNd, div.TCYkdd").First().Text()),
+		}
+		if event.Date == "" {
+			event.Date = normalizeSpace(s.Find("div.UIaQzd").First().Text() + " " + s.Find("div.wsnHcb").First().Text())
+		}
+		if href, ok := s.Find("a[href]").First().Attr("href"); ok {
+			event.URL = resolveGoogleURL(doc.Url, href)
+		}
+		if event.Name != "" {
+			events = append(events, event)
+		}
+	})
+	return events
+}
+
+type Job struct {
+	Title    string
+	Company  string
+	Location string
+	Posted   string
+	Via      string
+	URL      string
+}
+
+func extractJobs(doc *goquery.Document) []Job {
+	var jobs []Job
+	doc.Find("li.iFjolb, div.PwjeAc").Each(func(i int, s *goquery.Selection) {
+		details := s.Find("div.Qk80Jf")
+		job := Job{
+			Title:    normalizeSpace(s.Find("div.BjJfJf").First().Text()),
+			Company:  normalizeSpace(s.Find("div.vNEEBe").First().Text()),
+			Location: normalizeSpace(details.Eq(0).Text()),
+			Via:      strings.TrimPrefix(normalizeSpace(details.Eq(1).Text()), "via "),
+			Posted:   normalizeSpace(s.Find("span.LL4CDc").First().Text()),
+		}
+		if href, ok := s.Find("a[href]").First().Attr("href"); ok {
+			job.URL = resolveGoogleURL(doc.Url, href)
+		}
+		if job.Title != "" {
+			jobs = append(jobs, job)
+		}
+	})
+	return jobs
+}
+
+type RecipeResult struct {
+	Title     string
+	URL       string
+	Source    string
+	Rating    float64
+	Reviews   string
+	CookTime  string
+	Thumbnail string
+}
+
+func extractRecipes(doc *goquery.Document) []RecipeResult {
+	var recipes []RecipeResult
+	doc.Find("div.YwonT, g-inner-card:has(span.z3HNkc)").Each(func(i int, s *goquery.Selection) {
+		link := s.Find("a[href]").First()
+		recipe := RecipeResult{
+			Title:     normalizeSpace(s.Find("div.hfac6d, div.jvNYyc, [role='heading']").First().Text()),
+			URL:       resolveGoogleURL(doc.Url, link.AttrOr("href", "")),
+			Source:    normalizeSpace(s.Find("cite, span.KuNgxf").First().Text()),
+			Reviews:   strings.Trim(normalizeSpace(s.Find("span.HypWnf").First().Text()), "()"),
+			Thumbnail: s.Find("img").First().AttrOr("src", ""),
+		}
+		if rating := s.Find("span.z3HNkc, span.YDIN4c").First(); rating.Length() > 0 {
+			recipe.Rating = parseRating(rating.AttrOr("aria-label", rating.Text()))
+		}
+		for _, text := range leafTexts(s) {
+			if durationPattern.MatchString(text) {
+				recipe.CookTime = text
+				break
+			}
+		}
+		if recipe.Title != "" {
+			recipes = append(recipes, recipe)
+		}
+	})
+	return recipes
+}
+
+var ratingPattern = regexp.MustCompile(`\d+(?:[.,]\d+)?`)
+
+// parseRating reads the first number of labels like "Rated 4.8 out of 5".
+func parseRating(label string) float64 {
+	match := ratingPattern.FindString(label)
+	if match == "" {
+		return 0
+	}
+	rating, err := strconv.ParseFloat(strings.Replace(match, ",", ".", 1), 64)
+	if err != nil {
+		return 0
+	}
+	return rating
+}
+
+type SocialPost struct {
+	Author    string
+	Text      string
+	Timestamp string
+	URL       string
+}
+
+var (
+	socialHostPattern   = regexp.MustCompile(`^https?://(www\.|mobile\.)?(twitter|x)\.com/`)
+	relativeTimePattern = regexp.MustCompile(`(?i)^\d+\s*(s|m|h|d|w|mo|y|sec|secs|min|mins|hour|hours|day|days|week|weeks|month|months|year|years)(\s+ago)?$`)
+)
+
+func extractSocialPosts(doc *goquery.Document) []SocialPost {
+	var posts []SocialPost
+	doc.Find("g-section-with-header").Each(func(i int, section *goquery.Selection) {
+		header := section.Find("a[href]").First()
+		if !socialHostPattern.MatchString(resolveGoogleURL(doc.Url, header.AttrOr("href", ""))) {
+			return
+		}
+		author := normalizeSpace(section.Find("h3, [role='heading']").First().Text())
+
+		section.Find("g-inner-card").Each(func(i int, card *goquery.Selection) {
+			post := SocialPost{Author: author}
+			card.Find("a[href]").EachWithBreak(func(i int, a *goquery.Selection) bool {
+				href := resolveGoogleURL(doc.Url, a.AttrOr("href", ""))
+				if strings.Contains(href, "/status/") {
+					post.URL = href
+					return false
+				}
+				return true
+			})
+			for _, text := range leafTexts(card) {
+				switch {
+				case post.Timestamp == "" && relativeTimePattern.MatchString(text):
+					post.Timestamp = text
+				case len(text) > len(post.Text):
+					post.Text = text
+				}
+			}
+			if post.Text != "" {
+				posts = append(posts, post)
+			}
+		})
+	})
+	return posts
+}
+
+type Video struct {
+	Title      string
+	URL        string
+	Platform   string
+	Channel    string
+	Duration   string
+	KeyMoments []VideoKeyMoment
+}
+
+type VideoKeyMoment struct {
+	Time  string
+	Label string
+	URL   string
+}
+
+var clockPattern = regexp.MustCompile(`^\d{1,2}:\d{2}(:\d{2})?$`)
+
+func extractVideos(doc *goquery.Document) []Video {
+	var videos []Video
+	doc.Find("video-voyager, div.RzdJxc").Each(func(i int, s *goquery.Selection) {
+		video := Video{
+			Title: normalizeSpace(s.Find("[role='heading'], div.fc9yUc").First().Text()),
+			URL:   resolveGoogleURL(doc.Url, s.Find("a[href]").First().AttrOr("href", "")),
+		}
+		source := normalizeSpace(s.Find("cite, span.pcJO7e").First().Text())
+		if platform, channel, ok := strings.Cut(source, " · "); ok {
+			video.Platform, video.Channel = platform, channel
+		} else {
+			video.Platform = source
+		}
+
+		for _, text := range leafTexts(s) {
+			if clockPattern.MatchString(text) {
+				video.Duration = text
+				break
+			}
+		}
+
+		s.Find("a[href]").Each(func(i int, a *goquery.Selection) {
+			href := resolveGoogleURL(doc.Url, a.AttrOr("href", ""))
+			u, err := url.Parse(href)
+			if err != nil || href == video.URL || u.Query().Get("t") == "" {
+				return
+			}
+			moment := VideoKeyMoment{URL: href, Time: u.Query().Get("t")}
+			for _, text := range leafTexts(a) {
+				if clockPattern.MatchString(text) {
+					moment.Time = text
+				} else if moment.Label == "" {
+					moment.Label = text
+				}
+			}
+			video.KeyMoments = append(video.KeyMoments, moment)
+		})
+
+		if video.Title != "" {
+			videos = append(videos, video)
+		}
+	})
+	return videos
+}
+
+type InlineImage struct {
+	Thumbnail string
+	ImageURL  string
+	SourceURL string
+	Title     string
+}
+
+func extractImagePack(doc *goquery.Document) []InlineImage {
+	var images []InlineImage
+	doc.Find("div#iur a[href], div#imagebox_bigimages a[href]").Each(func(i int, a *goquery.Selection) {
+		img := a.Find("img").First()
+		if img.Length() == 0 {
+			return
+		}
+		image := InlineImage{
+			Thumbnail: img.AttrOr("data-src", img.AttrOr("src", "")),
+			Title:     img.AttrOr("alt", ""),
+		}
+		if strings.HasPrefix(image.Thumbnail, "data:") {
+			image.Thumbnail = ""
+		}
+
+		href := a.AttrOr("href", "")
+		if u, err := url.Parse(href); err == nil && strings.Contains(u.Path, "imgres") {
+			image.ImageURL = u.Query().Get("imgurl")
+			image.SourceURL = u.Query().Get("imgrefurl")
+		} else {
+			image.SourceURL = resolveGoogleURL(doc.Url, href)
+		}
+		images = append(images, image)
+	})
+	return images
+}
+
+type LocalResult struct {
+	Name    string
+	Rating  float64
+	Reviews string
+	Details []string
+	URL     string
+	// CID is Google's customer id for the listing (the ludocid parameter).
+	CID string
+	// FeatureID is the hex "0x...:0x..." identifier used by Maps links.
+	FeatureID string
+	PlaceID   string
+	Latitude  float64
+	Longitude float64
+}
+
+var (
+	mapsAtPattern       = regexp.MustCompile(`@(-?\d+\.\d+),(-?\d+\.\d+)`)
+	mapsDataPattern     = regexp.MustCompile(`!3d(-?\d+\.\d+)!4d(-?\d+\.\d+)`)
+	featureIDPattern    = regexp.MustCompile(`0x[0-9a-f]+:0x[0-9a-f]+`)
+	placeIDParamPattern = regexp.MustCompile(`place_id[:=]([A-Za-z0-9_-]+)`)
+)
+
+func extractLocalPack(doc *goquery.Document) []LocalResult {
+	var places []LocalResult
+	doc.Find("div.VkpGBb").Each(func(i int, s *goquery.Selection) {
+		place := LocalResult{
+			Name:    normalizeSpace(s.Find("div.dbg0pd, span.OSrXXb").First().Text()),
+			Rating:  parseRating(s.Find("span.yi40Hd").First().Text()),
+			Reviews: strings.Trim(normalizeSpace(s.Find("span.RDApEe").First().Text()), "()"),
+		}
+		s.Find("div.rllt__details > div").Each(func(i int, detail *goquery.Selection) {
+			if text := normalizeSpace(detail.Text()); text != "" {
+				place.Details = append(place.Details, text)
+			}
+		})
+		extractPlaceIdentifiers(s, doc.Url, &place)
+		if place.Name != "" {
+			places = append(places, place)
+		}
+	})
+	return places
+}
+
+// extractPlaceIdentifiers collects coordinates and ids from data attributes
+// and Maps links of a local pack entry.
+func extractPlaceIdentifiers(s *goquery.Selection, base *url.URL, place *LocalResult) {
+	nodes := s.Find("*").AddSelection(s)
+	nodes.Each(func(i int, el *goquery.Selection) {
+		if place.CID == "" {
+			place.CID = el.AttrOr("data-cid", "")
+		}
+		if place.FeatureID == "" {
+			place.FeatureID = el.AttrOr("data-fid", "")
+		}
+		if place.PlaceID == "" {
+			place.PlaceID = el.AttrOr("data-pid", "")
+		}
+		if place.Latitude == 0 && place.Longitude == 0 {
+			lat, errLat := strconv.ParseFloat(el.AttrOr("data-lat", ""), 64)
+			lng, errLng := strconv.ParseFloat(el.AttrOr("data-lng", ""), 64)
+			if errLat == nil && errLng == nil {
+				place.Latitude, place.Longitude = lat, lng
+			}
+		}
+	})
+
+	s.Find("a[href]").Each(func(i int, a *goquery.Selection) {
+		href := a.AttrOr("href", "")
+		if place.URL == "" && !strings.HasPrefix(href, "#") {
+			place.URL = resolveGoogleURL(base, href)
+		}
+		if u, err := url.Parse(href); err == nil && place.CID == "" {
+			place.CID = u.Query().Get("ludocid")
+		}
+		if place.FeatureID == "" {
+			place.FeatureID = featureIDPattern.FindString(href)
+		}
+		if place.PlaceID == "" {
+			if m := placeIDParamPattern.FindStringSubmatch(href); m != nil {
+				place.PlaceID = m[1]
+			}
+		}
+		if place.Latitude == 0 && place.Longitude == 0 {
+			m := mapsDataPattern.FindStringSubmatch(href)
+			if m == nil {
+				m = mapsAtPattern.FindStringSubmatch(href)
+			}
+			if m != nil {
+				place.Latitude, _ = strconv.ParseFloat(m[1], 64)
+				place.Longitude, _ = strconv.ParseFloat(m[2], 64)
+			}
+		}
+	})
+}
+
+func extractRelatedSearches(doc *goquery.Document) []string {
+	var related []string
+	seen := map[string]bool{}
+	doc.Find("a.k8XOCe, div.s75CSd, a.ngTNl, div.gGQDvd a").Each(func(i int, s *goquery.Selection) {
+		text := normalizeSpace(s.Text())
+		if text != "" && !seen[text] {
+			seen[text] = true
+			related = append(related, text)
+		}
+	})
+	return related
+}
+
+func extractPeopleAlsoAsk(doc *goquery.Document) []string {
+	var questions []string
+	doc.Find("div.related-question-pair").Each(func(i int, s *goquery.Selection) {
+		question := s.AttrOr("data-q", "")
+		if question == "" {
+			question = normalizeSpace(s.Find("[role='button'] span").First().Text())
+		}
+		if question != "" {
+			questions = append(questions, question)
+		}
+	})
+	return questions
+}
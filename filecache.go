@@ -0,0 +1,145 @@
+package googlesearch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileCache is a Cache storing one file per key in a directory, so cached
+// searches survive restarts. It is safe for concurrent use within a process
+// and across processes sharing the directory, as writes are atomic renames.
+type FileCache struct {
+	dir string
+}
+
+// CacheEntry describes an entry listed by FileCache.Entries.
+type CacheEntry struct {
+	Key     string
+	Size    int
+	Expires time.Time
+	Value   []byte
+}
+
+type fileCacheRecord struct {
+	Key     string    `json:"key"`
+	Expires time.Time `json:"expires,omitempty"`
+	Value   []byte    `json:"value"`
+}
+
+// NewFileCache returns a FileCache in dir, creating the directory if needed.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("google: cache directory: %w", err)
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+func (c *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *FileCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	rec, err := readCacheRecord(c.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if !rec.Expires.IsZero() && time.Now().After(rec.Expires) {
+		os.Remove(c.path(key))
+		return nil, false, nil
+	}
+	return rec.Value, true, nil
+}
+
+func (c *FileCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	rec := fileCacheRecord{Key: key, Value: value}
+	if ttl > 0 {
+		rec.Expires = time.Now().Add(ttl)
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+func (c *FileCache) Delete(ctx context.Context, key string) error {
+	err := os.Remove(c.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Entries lists the unexpired entries, ordered by key.
+func (c *FileCache) Entries(ctx context.Context) ([]CacheEntry, error) {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var entries []CacheEntry
+	for _, file := range files {
+		rec, err := readCacheRecord(file)
+		if err != nil {
+			continue
+		}
+		if !rec.Expires.IsZero() && time.Now().After(rec.Expires) {
+			continue
+		}
+		entries = append(entries, CacheEntry{Key: rec.Key, Size: len(rec.Value), Expires: rec.Expires, Value: rec.Value})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
+
+// Clear removes all entries.
+func (c *FileCache) Clear(ctx context.Context) error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".json") || strings.HasPrefix(f.Name(), ".tmp-") {
+			if err := os.Remove(filepath.Join(c.dir, f.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readCacheRecord(path string) (fileCacheRecord, error) {
+	var rec fileCacheRecord
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rec, err
+	}
+	err = json.Unmarshal(data, &rec)
+	return rec, err
+}
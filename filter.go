@@ -0,0 +1,73 @@
+package googlesearch
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// googleInternalHosts are Google properties whose links show up in the
+// organic blocks but are navigation rather than results.
+var googleInternalHosts = []string{
+	"maps.google.",
+	"translate.google.",
+	"accounts.google.",
+	"support.google.",
+	"policies.google.",
+	"webcache.googleusercontent.com",
+}
+
+// isWebResult reports whether rawURL is an http(s) link outside Google's
+// own navigation.
+func isWebResult(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "http" && scheme != "https" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, internal := range googleInternalHosts {
+		if strings.HasPrefix(host, internal) || host == internal {
+			return false
+		}
+	}
+	if (host == "www.google.com" || strings.HasPrefix(host, "www.google.")) &&
+		(u.Path == "/search" || strings.HasPrefix(u.Path, "/maps") || u.Path == "/url") {
+		return false
+	}
+	return true
+}
+
+func filterWebResults(results []SearchResult) []SearchResult {
+	filtered := results[:0]
+	for _, result := range results {
+		if isWebResult(result.URL) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// extractCachedURL looks for the "Cached" link Google shows for some
+// results and returns its absolute URL.
+func extractCachedURL(s *goquery.Selection, base *url.URL) string {
+	var cached string
+	s.Find("a[href]").EachWithBreak(func(i int, a *goquery.Selection) bool {
+		href := resolveGoogleURL(base, a.AttrOr("href", ""))
+		u, err := url.Parse(href)
+		if err != nil {
+			return true
+		}
+		if strings.HasPrefix(u.Hostname(), "webcache.googleusercontent.com") ||
+			strings.HasPrefix(u.Query().Get("q"), "cache:") {
+			cached = href
+			return false
+		}
+		return true
+	})
+	return cached
+}
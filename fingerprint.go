@@ -0,0 +1,44 @@
+package googlesearch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// Fingerprint returns a stable hash of results that changes whenever a
+// result's URL, title or description changes or the ranking order does.
+// Monitoring jobs can compare fingerprints between runs before doing a full
+// diff.
+func Fingerprint(results []SearchResult) string {
+	h := sha256.New()
+	for _, result := range results {
+		h.Write(resultDigest(result))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FingerprintUnordered is like Fingerprint but ignores the order of
+// results, so pure reshuffles of the same set hash identically.
+func FingerprintUnordered(results []SearchResult) string {
+	digests := make([]string, len(results))
+	for i, result := range results {
+		digests[i] = string(resultDigest(result))
+	}
+	sort.Strings(digests)
+
+	h := sha256.New()
+	for _, digest := range digests {
+		h.Write([]byte(digest))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func resultDigest(result SearchResult) []byte {
+	h := sha256.New()
+	for _, field := range []string{result.URL, result.Title, result.Description} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,100 @@
+package googlesearch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// FormatData is the value a SERP template is executed with.
+type FormatData struct {
+	Query    string
+	Results  []SearchResult
+	Metadata *SERPMetadata
+	// Body holds the concatenated output of the per-result template.
+	Body string
+}
+
+// FormatResult is the value a per-result template is executed with.
+type FormatResult struct {
+	SearchResult
+	// Rank is the 1-based position of the result.
+	Rank int
+}
+
+// Built-in per-result templates, usable by name with NewTemplateFormatter.
+var builtinFormats = map[string]string{
+	"markdown": "{{.Rank}}. [{{.Title}}]({{.URL}})\n{{if .Description}}   {{.Description}}\n{{end}}",
+	"org":      "- [[{{.URL}}][{{.Title}}]]\n{{if .Description}}  {{.Description}}\n{{end}}",
+	"csv":      "{{.Rank}},{{csv .URL}},{{csv .Title}},{{csv .Description}}\n",
+	"urls":     "{{.URL}}\n",
+}
+
+var formatFuncs = template.FuncMap{
+	"trunc": func(n int, s string) string {
+		r := []rune(s)
+		if len(r) <= n {
+			return s
+		}
+		return string(r[:n]) + "…"
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"csv": func(s string) string {
+		if strings.ContainsAny(s, "\",\n") {
+			return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+		}
+		return s
+	},
+}
+
+// TemplateFormatter renders results through user supplied text/templates.
+type TemplateFormatter struct {
+	result *template.Template
+	serp   *template.Template
+}
+
+// NewTemplateFormatter parses resultTmpl, applied to every FormatResult,
+// and serpTmpl, applied once to the FormatData of the whole search. Either
+// may be empty; resultTmpl may also name a built-in format ("markdown",
+// "org", "csv" or "urls"). Templates can use the trunc, upper, lower and
+// csv functions.
+func NewTemplateFormatter(resultTmpl, serpTmpl string) (*TemplateFormatter, error) {
+	if builtin, ok := builtinFormats[resultTmpl]; ok {
+		resultTmpl = builtin
+	}
+	f := &TemplateFormatter{}
+	var err error
+	if resultTmpl != "" {
+		if f.result, err = template.New("result").Funcs(formatFuncs).Parse(resultTmpl); err != nil {
+			return nil, fmt.Errorf("google: result template: %w", err)
+		}
+	}
+	if serpTmpl != "" {
+		if f.serp, err = template.New("serp").Funcs(formatFuncs).Parse(serpTmpl); err != nil {
+			return nil, fmt.Errorf("google: SERP template: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// Format writes data to w. Without a SERP template only the per-result
+// output is written.
+func (f *TemplateFormatter) Format(w io.Writer, data FormatData) error {
+	var body bytes.Buffer
+	if f.result != nil {
+		for i, result := range data.Results {
+			if err := f.result.Execute(&body, FormatResult{SearchResult: result, Rank: i + 1}); err != nil {
+				return err
+			}
+		}
+	}
+	if f.serp == nil {
+		_, err := w.Write(body.Bytes())
+		return err
+	}
+	data.Body = body.String()
+	return f.serp.Execute(w, data)
+}
@@ -0,0 +1,5 @@
+// Package googlesearchpb holds the protobuf and gRPC definitions used by
+// cmd/googlesearchd.
+package googlesearchpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative googlesearch.proto
@@ -0,0 +1,364 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: googlesearch.proto
+
+package googlesearchpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	NumResults    int32                  `protobuf:"varint,2,opt,name=num_results,json=numResults,proto3" json:"num_results,omitempty"`
+	Lang          string                 `protobuf:"bytes,3,opt,name=lang,proto3" json:"lang,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_googlesearch_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_googlesearch_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_googlesearch_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetNumResults() int32 {
+	if x != nil {
+		return x.NumResults
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+type SearchAdvancedRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Query      string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	NumResults int32                  `protobuf:"varint,2,opt,name=num_results,json=numResults,proto3" json:"num_results,omitempty"`
+	Lang       string                 `protobuf:"bytes,3,opt,name=lang,proto3" json:"lang,omitempty"`
+	Region     string                 `protobuf:"bytes,4,opt,name=region,proto3" json:"region,omitempty"`
+	// Location is a canonical location name or a raw uule value.
+	Location string `protobuf:"bytes,5,opt,name=location,proto3" json:"location,omitempty"`
+	// Safe is "active" or "off".
+	Safe   string `protobuf:"bytes,6,opt,name=safe,proto3" json:"safe,omitempty"`
+	Start  int32  `protobuf:"varint,7,opt,name=start,proto3" json:"start,omitempty"`
+	Unique bool   `protobuf:"varint,8,opt,name=unique,proto3" json:"unique,omitempty"`
+	// Tbs is passed through as Google's tbs parameter, e.g. "qdr:w".
+	Tbs           string `protobuf:"bytes,9,opt,name=tbs,proto3" json:"tbs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchAdvancedRequest) Reset() {
+	*x = SearchAdvancedRequest{}
+	mi := &file_googlesearch_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchAdvancedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchAdvancedRequest) ProtoMessage() {}
+
+func (x *SearchAdvancedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_googlesearch_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchAdvancedRequest.ProtoReflect.Descriptor instead.
+func (*SearchAdvancedRequest) Descriptor() ([]byte, []int) {
+	return file_googlesearch_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SearchAdvancedRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchAdvancedRequest) GetNumResults() int32 {
+	if x != nil {
+		return x.NumResults
+	}
+	return 0
+}
+
+func (x *SearchAdvancedRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *SearchAdvancedRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *SearchAdvancedRequest) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *SearchAdvancedRequest) GetSafe() string {
+	if x != nil {
+		return x.Safe
+	}
+	return ""
+}
+
+func (x *SearchAdvancedRequest) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *SearchAdvancedRequest) GetUnique() bool {
+	if x != nil {
+		return x.Unique
+	}
+	return false
+}
+
+func (x *SearchAdvancedRequest) GetTbs() string {
+	if x != nil {
+		return x.Tbs
+	}
+	return ""
+}
+
+type SearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rank          int32                  `protobuf:"varint,1,opt,name=rank,proto3" json:"rank,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	DisplayUrl    string                 `protobuf:"bytes,4,opt,name=display_url,json=displayUrl,proto3" json:"display_url,omitempty"`
+	Title         string                 `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_googlesearch_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_googlesearch_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_googlesearch_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SearchResult) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *SearchResult) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *SearchResult) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *SearchResult) GetDisplayUrl() string {
+	if x != nil {
+		return x.DisplayUrl
+	}
+	return ""
+}
+
+func (x *SearchResult) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SearchResult) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+var File_googlesearch_proto protoreflect.FileDescriptor
+
+const file_googlesearch_proto_rawDesc = "" +
+	"\n" +
+	"\x12googlesearch.proto\x12\x0fgooglesearch.v1\"Z\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1f\n" +
+	"\vnum_results\x18\x02 \x01(\x05R\n" +
+	"numResults\x12\x12\n" +
+	"\x04lang\x18\x03 \x01(\tR\x04lang\"\xea\x01\n" +
+	"\x15SearchAdvancedRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1f\n" +
+	"\vnum_results\x18\x02 \x01(\x05R\n" +
+	"numResults\x12\x12\n" +
+	"\x04lang\x18\x03 \x01(\tR\x04lang\x12\x16\n" +
+	"\x06region\x18\x04 \x01(\tR\x06region\x12\x1a\n" +
+	"\blocation\x18\x05 \x01(\tR\blocation\x12\x12\n" +
+	"\x04safe\x18\x06 \x01(\tR\x04safe\x12\x14\n" +
+	"\x05start\x18\a \x01(\x05R\x05start\x12\x16\n" +
+	"\x06unique\x18\b \x01(\bR\x06unique\x12\x10\n" +
+	"\x03tbs\x18\t \x01(\tR\x03tbs\"\xa1\x01\n" +
+	"\fSearchResult\x12\x12\n" +
+	"\x04rank\x18\x01 \x01(\x05R\x04rank\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12\x1f\n" +
+	"\vdisplay_url\x18\x04 \x01(\tR\n" +
+	"displayUrl\x12\x14\n" +
+	"\x05title\x18\x05 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription2\xb4\x01\n" +
+	"\fGoogleSearch\x12I\n" +
+	"\x06Search\x12\x1e.googlesearch.v1.SearchRequest\x1a\x1d.googlesearch.v1.SearchResult0\x01\x12Y\n" +
+	"\x0eSearchAdvanced\x12&.googlesearch.v1.SearchAdvancedRequest\x1a\x1d.googlesearch.v1.SearchResult0\x01B/Z-github.com/1hehaq/googlesearch/googlesearchpbb\x06proto3"
+
+var (
+	file_googlesearch_proto_rawDescOnce sync.Once
+	file_googlesearch_proto_rawDescData []byte
+)
+
+func file_googlesearch_proto_rawDescGZIP() []byte {
+	file_googlesearch_proto_rawDescOnce.Do(func() {
+		file_googlesearch_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_googlesearch_proto_rawDesc), len(file_googlesearch_proto_rawDesc)))
+	})
+	return file_googlesearch_proto_rawDescData
+}
+
+var file_googlesearch_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_googlesearch_proto_goTypes = []any{
+	(*SearchRequest)(nil),         // 0: googlesearch.v1.SearchRequest
+	(*SearchAdvancedRequest)(nil), // 1: googlesearch.v1.SearchAdvancedRequest
+	(*SearchResult)(nil),          // 2: googlesearch.v1.SearchResult
+}
+var file_googlesearch_proto_depIdxs = []int32{
+	0, // 0: googlesearch.v1.GoogleSearch.Search:input_type -> googlesearch.v1.SearchRequest
+	1, // 1: googlesearch.v1.GoogleSearch.SearchAdvanced:input_type -> googlesearch.v1.SearchAdvancedRequest
+	2, // 2: googlesearch.v1.GoogleSearch.Search:output_type -> googlesearch.v1.SearchResult
+	2, // 3: googlesearch.v1.GoogleSearch.SearchAdvanced:output_type -> googlesearch.v1.SearchResult
+	2, // [2:4] is the sub-list for method output_type
+	0, // [0:2] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_googlesearch_proto_init() }
+func file_googlesearch_proto_init() {
+	if File_googlesearch_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_googlesearch_proto_rawDesc), len(file_googlesearch_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_googlesearch_proto_goTypes,
+		DependencyIndexes: file_googlesearch_proto_depIdxs,
+		MessageInfos:      file_googlesearch_proto_msgTypes,
+	}.Build()
+	File_googlesearch_proto = out.File
+	file_googlesearch_proto_goTypes = nil
+	file_googlesearch_proto_depIdxs = nil
+}
@@ -0,0 +1,171 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: googlesearch.proto
+
+package googlesearchpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GoogleSearch_Search_FullMethodName         = "/googlesearch.v1.GoogleSearch/Search"
+	GoogleSearch_SearchAdvanced_FullMethodName = "/googlesearch.v1.GoogleSearch/SearchAdvanced"
+)
+
+// GoogleSearchClient is the client API for GoogleSearch service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GoogleSearch exposes the googlesearch package over gRPC. Both calls stream
+// results as soon as each results page is parsed.
+type GoogleSearchClient interface {
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchResult], error)
+	SearchAdvanced(ctx context.Context, in *SearchAdvancedRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchResult], error)
+}
+
+type googleSearchClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGoogleSearchClient(cc grpc.ClientConnInterface) GoogleSearchClient {
+	return &googleSearchClient{cc}
+}
+
+func (c *googleSearchClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GoogleSearch_ServiceDesc.Streams[0], GoogleSearch_Search_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SearchRequest, SearchResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GoogleSearch_SearchClient = grpc.ServerStreamingClient[SearchResult]
+
+func (c *googleSearchClient) SearchAdvanced(ctx context.Context, in *SearchAdvancedRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GoogleSearch_ServiceDesc.Streams[1], GoogleSearch_SearchAdvanced_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SearchAdvancedRequest, SearchResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GoogleSearch_SearchAdvancedClient = grpc.ServerStreamingClient[SearchResult]
+
+// GoogleSearchServer is the server API for GoogleSearch service.
+// All implementations must embed UnimplementedGoogleSearchServer
+// for forward compatibility.
+//
+// GoogleSearch exposes the googlesearch package over gRPC. Both calls stream
+// results as soon as each results page is parsed.
+type GoogleSearchServer interface {
+	Search(*SearchRequest, grpc.ServerStreamingServer[SearchResult]) error
+	SearchAdvanced(*SearchAdvancedRequest, grpc.ServerStreamingServer[SearchResult]) error
+	mustEmbedUnimplementedGoogleSearchServer()
+}
+
+// UnimplementedGoogleSearchServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGoogleSearchServer struct{}
+
+func (UnimplementedGoogleSearchServer) Search(*SearchRequest, grpc.ServerStreamingServer[SearchResult]) error {
+	return status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedGoogleSearchServer) SearchAdvanced(*SearchAdvancedRequest, grpc.ServerStreamingServer[SearchResult]) error {
+	return status.Errorf(codes.Unimplemented, "method SearchAdvanced not implemented")
+}
+func (UnimplementedGoogleSearchServer) mustEmbedUnimplementedGoogleSearchServer() {}
+func (UnimplementedGoogleSearchServer) testEmbeddedByValue()                      {}
+
+// UnsafeGoogleSearchServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GoogleSearchServer will
+// result in compilation errors.
+type UnsafeGoogleSearchServer interface {
+	mustEmbedUnimplementedGoogleSearchServer()
+}
+
+func RegisterGoogleSearchServer(s grpc.ServiceRegistrar, srv GoogleSearchServer) {
+	// If the following call pancis, it indicates UnimplementedGoogleSearchServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GoogleSearch_ServiceDesc, srv)
+}
+
+func _GoogleSearch_Search_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GoogleSearchServer).Search(m, &grpc.GenericServerStream[SearchRequest, SearchResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GoogleSearch_SearchServer = grpc.ServerStreamingServer[SearchResult]
+
+func _GoogleSearch_SearchAdvanced_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchAdvancedRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GoogleSearchServer).SearchAdvanced(m, &grpc.GenericServerStream[SearchAdvancedRequest, SearchResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GoogleSearch_SearchAdvancedServer = grpc.ServerStreamingServer[SearchResult]
+
+// GoogleSearch_ServiceDesc is the grpc.ServiceDesc for GoogleSearch service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GoogleSearch_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "googlesearch.v1.GoogleSearch",
+	HandlerType: (*GoogleSearchServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Search",
+			Handler:       _GoogleSearch_Search_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SearchAdvanced",
+			Handler:       _GoogleSearch_SearchAdvanced_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "googlesearch.proto",
+}
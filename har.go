@@ -0,0 +1,222 @@
+package googlesearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const harRedacted = "[REDACTED]"
+
+// HARRecorder records every request and response of the searches it is
+// attached to (SearchOptions.HARRecorder) so sessions can be inspected in
+// browser dev tools or shared when reporting blocks. Cookie and
+// authorization values are redacted. It is safe for concurrent use.
+type HARRecorder struct {
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+func NewHARRecorder() *HARRecorder {
+	return &HARRecorder{}
+}
+
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Headers     []harPair   `json:"headers"`
+	QueryString []harPair   `json:"queryString"`
+	Cookies     []harCookie `json:"cookies"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Headers     []harPair   `json:"headers"`
+	Cookies     []harCookie `json:"cookies"`
+	Content     harContent  `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type harPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// WriteTo writes the recorded session as a HAR 1.2 document.
+func (r *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	var doc harLog
+	doc.Log.Version = "1.2"
+	doc.Log.Creator = harCreator{Name: "googlesearch", Version: "1"}
+
+	r.mu.Lock()
+	doc.Log.Entries = append([]harEntry{}, r.entries...)
+	r.mu.Unlock()
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// WriteFile writes the recorded session to path.
+func (r *HARRecorder) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := r.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Reset discards all recorded entries.
+func (r *HARRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+type harTransport struct {
+	next     http.RoundTripper
+	recorder *HARRecorder
+}
+
+func (t *harTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	waited := time.Since(started)
+
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if readErr != nil {
+		return resp, readErr
+	}
+
+	entry := harEntry{
+		StartedDateTime: started,
+		Time:            milliseconds(time.Since(started)),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Headers:     harHeaders(req.Header),
+			Cookies:     harCookies(req.Cookies()),
+			HeadersSize: -1,
+			BodySize:    0,
+		},
+		Response: harResponse{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: resp.Proto,
+			Headers:     harHeaders(resp.Header),
+			Cookies:     harCookies(resp.Cookies()),
+			Content: harContent{
+				Size:     len(body),
+				MimeType: resp.Header.Get("Content-Type"),
+				Text:     string(body),
+			},
+			RedirectURL: resp.Header.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    len(body),
+		},
+		Timings: harTimings{
+			Wait:    milliseconds(waited),
+			Receive: milliseconds(time.Since(started) - waited),
+		},
+	}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, harPair{Name: name, Value: value})
+		}
+	}
+
+	t.recorder.mu.Lock()
+	t.recorder.entries = append(t.recorder.entries, entry)
+	t.recorder.mu.Unlock()
+	return resp, nil
+}
+
+func harHeaders(h http.Header) []harPair {
+	pairs := []harPair{}
+	for name, values := range h {
+		for _, value := range values {
+			switch strings.ToLower(name) {
+			case "cookie", "set-cookie", "authorization", "proxy-authorization":
+				value = harRedacted
+			}
+			pairs = append(pairs, harPair{Name: name, Value: value})
+		}
+	}
+	return pairs
+}
+
+func harCookies(cookies []*http.Cookie) []harCookie {
+	redacted := []harCookie{}
+	for _, cookie := range cookies {
+		redacted = append(redacted, harCookie{Name: cookie.Name, Value: harRedacted})
+	}
+	return redacted
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,131 @@
+package googlesearch
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+type browserFamily int
+
+const (
+	browserChromium browserFamily = iota
+	browserFirefox
+	browserSafari
+	browserText
+)
+
+var (
+	chromeVersionPattern = regexp.MustCompile(`Chrome/(\d+)`)
+	edgeVersionPattern   = regexp.MustCompile(`Edg(?:e|A|iOS)?/(\d+)`)
+)
+
+func detectBrowser(ua string) browserFamily {
+	switch {
+	case strings.HasPrefix(ua, "Lynx") || strings.HasPrefix(ua, "w3m") || strings.HasPrefix(ua, "Links"):
+		return browserText
+	case strings.Contains(ua, "Firefox/"):
+		return browserFirefox
+	case strings.Contains(ua, "Chrome/") || strings.Contains(ua, "Chromium/"):
+		return browserChromium
+	case strings.Contains(ua, "Safari/"):
+		return browserSafari
+	}
+	return browserChromium
+}
+
+func detectPlatform(ua string) string {
+	switch {
+	case strings.Contains(ua, "Android"):
+		return "Android"
+	case strings.Contains(ua, "iPhone") || strings.Contains(ua, "iPad"):
+		return "iOS"
+	case strings.Contains(ua, "Windows"):
+		return "Windows"
+	case strings.Contains(ua, "Mac OS X") || strings.Contains(ua, "Macintosh"):
+		return "macOS"
+	case strings.Contains(ua, "CrOS"):
+		return "Chrome OS"
+	case strings.Contains(ua, "Linux"):
+		return "Linux"
+	}
+	return "Unknown"
+}
+
+// acceptLanguage builds an Accept-Language value like "de-DE,de;q=0.9,en;q=0.8"
+// from the hl and gl settings.
+func acceptLanguage(lang, region string) string {
+	if lang == "" {
+		lang = "en"
+	}
+	primary := strings.ToLower(strings.SplitN(lang, "-", 2)[0])
+	tag := lang
+	if !strings.Contains(lang, "-") && region != "" {
+		tag = primary + "-" + strings.ToUpper(region)
+	}
+
+	values := []string{tag}
+	if tag != primary {
+		values = append(values, primary+";q=0.9")
+	}
+	if primary != "en" {
+		values = append(values, "en;q=0.8")
+	}
+	return strings.Join(values, ",")
+}
+
+// setBrowserHeaders sets the header set the browser identified by ua sends
+// for a top-level navigation. referer is the previous results page, if any.
+// net/http writes header fields in a fixed (sorted) order, so requests are
+// at least consistent with each other.
+func setBrowserHeaders(h http.Header, ua, lang, region, referer string) {
+	h.Set("User-Agent", ua)
+	h.Set("Accept-Language", acceptLanguage(lang, region))
+
+	family := detectBrowser(ua)
+	switch family {
+	case browserText:
+		h.Set("Accept", "text/html, text/plain, text/sgml, */*;q=0.01")
+		return
+	case browserFirefox:
+		h.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	case browserSafari:
+		h.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	default:
+		h.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7")
+	}
+
+	h.Set("Upgrade-Insecure-Requests", "1")
+	h.Set("Sec-Fetch-Dest", "document")
+	h.Set("Sec-Fetch-Mode", "navigate")
+	h.Set("Sec-Fetch-User", "?1")
+	if referer != "" {
+		h.Set("Referer", referer)
+		h.Set("Sec-Fetch-Site", "same-origin")
+	} else {
+		h.Set("Sec-Fetch-Site", "none")
+	}
+
+	if family == browserChromium {
+		platform := detectPlatform(ua)
+		mobile := "?0"
+		if strings.Contains(ua, "Mobile") {
+			mobile = "?1"
+		}
+		h.Set("Sec-Ch-Ua", clientHintBrands(ua))
+		h.Set("Sec-Ch-Ua-Mobile", mobile)
+		h.Set("Sec-Ch-Ua-Platform", fmt.Sprintf("%q", platform))
+	}
+}
+
+func clientHintBrands(ua string) string {
+	version := "120"
+	if m := chromeVersionPattern.FindStringSubmatch(ua); m != nil {
+		version = m[1]
+	}
+	if m := edgeVersionPattern.FindStringSubmatch(ua); m != nil {
+		return fmt.Sprintf(`"Chromium";v="%s", "Microsoft Edge";v="%s", "Not_A Brand";v="8"`, version, m[1])
+	}
+	return fmt.Sprintf(`"Chromium";v="%s", "Google Chrome";v="%s", "Not_A Brand";v="8"`, version, version)
+}
@@ -0,0 +1,47 @@
+package googlesearch
+
+// SearchHooks are lifecycle callbacks invoked synchronously from the
+// search loop. Any of them may be nil.
+type SearchHooks struct {
+	// OnResult is called for every result before it is delivered.
+	OnResult func(SearchResponse)
+	// OnPageComplete is called after each results page has been processed.
+	OnPageComplete func(PageInfo)
+	// OnError is called for every error the search encounters, including
+	// soft ones such as ErrNoResults for an empty page that do not abort it.
+	OnError func(page int, err error)
+}
+
+// PageInfo describes a completed results page.
+type PageInfo struct {
+	Page int
+	// Start is the value of the start parameter used for the page.
+	Start int
+	// Results is the number of organic results parsed from the page and
+	// NewResults how many of them were delivered.
+	Results    int
+	NewResults int
+	// Overlapping counts results skipped because the previous page already
+	// contained them.
+	Overlapping int
+	// Labels are those of the search, see WithLabels.
+	Labels Labels
+}
+
+func (h *SearchHooks) result(resp SearchResponse) {
+	if h != nil && h.OnResult != nil {
+		h.OnResult(resp)
+	}
+}
+
+func (h *SearchHooks) pageComplete(info PageInfo) {
+	if h != nil && h.OnPageComplete != nil {
+		h.OnPageComplete(info)
+	}
+}
+
+func (h *SearchHooks) error(page int, err error) {
+	if h != nil && h.OnError != nil {
+		h.OnError(page, err)
+	}
+}
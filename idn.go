@@ -0,0 +1,123 @@
+package googlesearch
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// normalizeResultURL returns the ASCII form of rawURL (punycode host,
+// percent-encoded path and query) for use in requests, and the display form
+// with a Unicode host and decoded non-ASCII characters as browsers show it.
+func normalizeResultURL(rawURL string) (ascii, display string, ok bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", "", false
+	}
+
+	host := u.Hostname()
+	asciiHost, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		asciiHost = strings.ToLower(host)
+	}
+	unicodeHost, err := idna.Display.ToUnicode(asciiHost)
+	if err != nil {
+		unicodeHost = asciiHost
+	}
+
+	asciiURL := *u
+	asciiURL.Host = joinHostPort(asciiHost, u.Port())
+	// Re-encode path and query so raw non-ASCII bytes become percent
+	// escapes while existing escapes such as %2F are preserved.
+	if u.RawPath != "" {
+		asciiURL.RawPath = encodeNonASCII(u.RawPath)
+	}
+	asciiURL.RawQuery = encodeNonASCII(u.RawQuery)
+	asciiURL.Fragment = u.Fragment
+	ascii = asciiURL.String()
+
+	displayURL := asciiURL
+	displayURL.Host = joinHostPort(unicodeHost, u.Port())
+	display = decodeNonASCII(displayURL.String())
+	return ascii, display, true
+}
+
+func joinHostPort(host, port string) string {
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port == "" {
+		return host
+	}
+	return host + ":" + port
+}
+
+// encodeNonASCII percent-encodes bytes outside the ASCII range and leaves
+// everything else, including existing escapes, untouched.
+func encodeNonASCII(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// decodeNonASCII decodes percent escapes that form valid non-ASCII UTF-8
+// sequences while keeping ASCII escapes such as %2F or %20 encoded, so the
+// result stays unambiguous.
+func decodeNonASCII(s string) string {
+	var b strings.Builder
+	var pending []byte
+	flush := func() {
+		for len(pending) > 0 {
+			r, size := utf8.DecodeRune(pending)
+			if r == utf8.RuneError && size <= 1 {
+				b.WriteString(encodeNonASCII(string(pending[:1])))
+				pending = pending[1:]
+				continue
+			}
+			b.Write(pending[:size])
+			pending = pending[size:]
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			if v, ok := unhex(s[i+1], s[i+2]); ok && v >= utf8.RuneSelf {
+				pending = append(pending, v)
+				i += 2
+				continue
+			}
+		}
+		flush()
+		b.WriteByte(s[i])
+	}
+	flush()
+	return b.String()
+}
+
+func unhex(hi, lo byte) (byte, bool) {
+	h, ok1 := fromHex(hi)
+	l, ok2 := fromHex(lo)
+	return h<<4 | l, ok1 && ok2
+}
+
+func fromHex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
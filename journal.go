@@ -0,0 +1,180 @@
+package googlesearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResultJournal writes the results of a search to a Store page by page as
+// they are delivered, instead of only once the search ends, so a search
+// that crashes or gets blocked on page 8 keeps pages 1 to 7. Every search
+// gets its own run, keyed by query and start time; a run is marked
+// complete once the search returns, with its error if it failed.
+type ResultJournal struct {
+	Store Store
+	// Prefix is prepended to all keys; it defaults to "journal/".
+	Prefix string
+	// OnError is called when a write fails. Journaling never fails the
+	// search itself.
+	OnError func(error)
+}
+
+// JournalRun is one journaled search as read back by Runs.
+type JournalRun struct {
+	Query     string
+	StartedAt time.Time
+	// Complete is false for searches that are still running or whose
+	// process died; Err is the error a completed search returned.
+	Complete bool
+	Err      string
+	Labels   Labels
+	Results  []SearchResponse
+}
+
+type journalPage struct {
+	Page    int
+	Results []SearchResponse
+}
+
+type journalEnd struct {
+	Err    string
+	Labels Labels
+}
+
+func (j *ResultJournal) prefix() string {
+	if j.Prefix == "" {
+		return "journal/"
+	}
+	return j.Prefix
+}
+
+func (j *ResultJournal) queryPrefix(query string) string {
+	return j.prefix() + url.PathEscape(query) + "/"
+}
+
+// Runs reads back the journaled searches for query, oldest first.
+func (j *ResultJournal) Runs(ctx context.Context, query string) ([]JournalRun, error) {
+	prefix := j.queryPrefix(query)
+	keys, err := j.Store.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var runs []JournalRun
+	for _, key := range keys {
+		rest := strings.TrimPrefix(key, prefix)
+		i := strings.Index(rest, "/")
+		if i < 0 {
+			continue
+		}
+		startedAt, err := time.Parse(archiveTimeFormat, rest[:i])
+		if err != nil {
+			continue
+		}
+		if len(runs) == 0 || !runs[len(runs)-1].StartedAt.Equal(startedAt) {
+			runs = append(runs, JournalRun{Query: query, StartedAt: startedAt})
+		}
+		run := &runs[len(runs)-1]
+
+		data, ok, err := j.Store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		switch name := rest[i+1:]; {
+		case name == "end.json":
+			var end journalEnd
+			if json.Unmarshal(data, &end) == nil {
+				run.Complete, run.Err, run.Labels = true, end.Err, end.Labels
+			}
+		case strings.HasPrefix(name, "page-"):
+			var page journalPage
+			if json.Unmarshal(data, &page) == nil {
+				run.Results = append(run.Results, page.Results...)
+				if run.Labels == nil && len(page.Results) > 0 {
+					run.Labels = page.Results[0].Labels
+				}
+			}
+		}
+	}
+	return runs, nil
+}
+
+// begin starts journaling a search; it returns nil without a journal.
+func (j *ResultJournal) begin(ctx context.Context, query string, labels Labels) *journalRun {
+	if j == nil || j.Store == nil {
+		return nil
+	}
+	return &journalRun{
+		journal: j,
+		ctx:     ctx,
+		labels:  labels,
+		prefix:  j.queryPrefix(query) + time.Now().UTC().Format(archiveTimeFormat) + "/",
+	}
+}
+
+// journalRun collects the results of the current page until it completes.
+type journalRun struct {
+	journal *ResultJournal
+	ctx     context.Context
+	labels  Labels
+	prefix  string
+
+	mu      sync.Mutex
+	pages   int
+	pending []SearchResponse
+}
+
+func (r *journalRun) add(resp SearchResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending = append(r.pending, resp)
+}
+
+// flush writes the results collected since the last flush as the next
+// page file.
+func (r *journalRun) flush() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.pending) == 0 {
+		return
+	}
+	r.pages++
+	page := journalPage{Page: r.pending[0].Page, Results: r.pending}
+	r.pending = nil
+	r.put(fmt.Sprintf("page-%04d.json", r.pages), page)
+}
+
+// finish flushes what is left and marks the run complete.
+func (r *journalRun) finish(err error) {
+	if r == nil {
+		return
+	}
+	r.flush()
+	end := journalEnd{Labels: r.labels}
+	if err != nil {
+		end.Err = err.Error()
+	}
+	r.put("end.json", end)
+}
+
+func (r *journalRun) put(name string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err == nil {
+		// Write even when the search was canceled, that is when the
+		// journal matters most.
+		err = r.journal.Store.Put(context.WithoutCancel(r.ctx), r.prefix+name, data)
+	}
+	if err != nil && r.journal.OnError != nil {
+		r.journal.OnError(err)
+	}
+}
@@ -0,0 +1,39 @@
+package googlesearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestJournalKeepsOnlyDeliveredResults(t *testing.T) {
+	google := newFakeGoogle(t, "lite")
+	proxy := newFakeProxy(t, google)
+	journal := &ResultJournal{Store: NewMemoryStore()}
+	opts := SearchOptions{NumResults: 3, Proxy: proxy.URL, InsecureSkipVerify: true, Journal: journal}
+
+	errStop := errors.New("consumer is done")
+	delivered := 0
+	ctx := context.Background()
+	_, err := walk(ctx, "golang", opts, func(SearchResponse) error {
+		if delivered == 1 {
+			return errStop
+		}
+		delivered++
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("walk returned %v, want the consumer's error", err)
+	}
+
+	runs, err := journal.Runs(ctx, "golang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("got %d journaled runs, want 1", len(runs))
+	}
+	if got := len(runs[0].Results); got != delivered {
+		t.Errorf("journaled %d results, the consumer took %d", got, delivered)
+	}
+}
@@ -0,0 +1,103 @@
+package googlesearch
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+const (
+	KeywordSourceSuggest  = "suggest"
+	KeywordSourceRelated  = "related"
+	KeywordSourceQuestion = "people_also_ask"
+)
+
+// Keyword is a candidate produced by ExpandKeyword. Score grows with the
+// number of sources that surfaced the keyword and with how prominently
+// each of them listed it.
+type Keyword struct {
+	Text    string
+	Score   float64
+	Sources []string
+}
+
+var keywordSourceWeights = map[string]float64{
+	KeywordSourceSuggest:  1.0,
+	KeywordSourceRelated:  0.8,
+	KeywordSourceQuestion: 0.6,
+}
+
+// ExpandKeyword combines autocomplete suggestions, related searches and
+// People Also Ask questions for seed into a deduplicated, scored keyword
+// set, sorted by descending score.
+func ExpandKeyword(ctx context.Context, seed string, opts SearchOptions) ([]Keyword, error) {
+	suggestions, err := Suggest(ctx, seed, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.NumResults <= 0 {
+		opts.NumResults = 10
+	}
+	_, metadata, err := search(ctx, seed, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	expander := keywordExpander{seed: normalizeKeyword(seed), byKey: map[string]*Keyword{}}
+	expander.add(KeywordSourceSuggest, suggestions)
+	expander.add(KeywordSourceRelated, metadata.RelatedSearches)
+	expander.add(KeywordSourceQuestion, metadata.PeopleAlsoAsk)
+	return expander.keywords(), nil
+}
+
+type keywordExpander struct {
+	seed  string
+	byKey map[string]*Keyword
+	order []*Keyword
+}
+
+func (e *keywordExpander) add(source string, texts []string) {
+	weight := keywordSourceWeights[source]
+	for i, text := range texts {
+		key := normalizeKeyword(text)
+		if key == "" || key == e.seed {
+			continue
+		}
+		keyword, ok := e.byKey[key]
+		if !ok {
+			keyword = &Keyword{Text: normalizeSpace(text)}
+			e.byKey[key] = keyword
+			e.order = append(e.order, keyword)
+		}
+		// Earlier positions count more; the decay keeps every entry positive.
+		keyword.Score += weight / float64(i+1)
+		if !containsString(keyword.Sources, source) {
+			keyword.Sources = append(keyword.Sources, source)
+		}
+	}
+}
+
+func (e *keywordExpander) keywords() []Keyword {
+	keywords := make([]Keyword, 0, len(e.order))
+	for _, keyword := range e.order {
+		keywords = append(keywords, *keyword)
+	}
+	sort.SliceStable(keywords, func(i, j int) bool {
+		return keywords[i].Score > keywords[j].Score
+	})
+	return keywords
+}
+
+func normalizeKeyword(s string) string {
+	return strings.ToLower(strings.TrimRight(normalizeSpace(s), "?"))
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package googlesearch
+
+import "context"
+
+// Labels are arbitrary key/value pairs, such as a job ID, tenant or keyword
+// group, attached to a search and echoed on everything it emits: every
+// SearchResponse, the PageInfo of SearchHooks, journal records, webhook
+// payloads and monitor runs. They do not affect the search itself.
+type Labels map[string]string
+
+type labelsKey struct{}
+
+// WithLabels returns a context carrying labels on top of those already in
+// ctx. Searches run with it report the combined labels, taking precedence
+// over SearchOptions.Labels.
+func WithLabels(ctx context.Context, labels Labels) context.Context {
+	merged := mergeLabels(LabelsFromContext(ctx), labels)
+	return context.WithValue(ctx, labelsKey{}, merged)
+}
+
+// LabelsFromContext returns the labels attached to ctx with WithLabels.
+func LabelsFromContext(ctx context.Context) Labels {
+	labels, _ := ctx.Value(labelsKey{}).(Labels)
+	return labels
+}
+
+// searchLabels returns the labels of a search: opts.Labels overridden by
+// those of ctx. It returns nil when there are none.
+func searchLabels(ctx context.Context, opts SearchOptions) Labels {
+	return mergeLabels(opts.Labels, LabelsFromContext(ctx))
+}
+
+// mergeLabels returns a new map with the labels of base and over, those of
+// over winning.
+func mergeLabels(base, over Labels) Labels {
+	if len(base) == 0 && len(over) == 0 {
+		return nil
+	}
+	merged := make(Labels, len(base)+len(over))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range over {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,168 @@
+package googlesearch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Language is an interface language as understood by Google's hl
+// parameter, such as "en", "en-GB" or "zh-CN". Use ParseLanguage to turn
+// user input into one; SearchOptions validates the value before use.
+type Language string
+
+// Region is a country as understood by Google's gl parameter: a lower case
+// ISO 3166-1 alpha-2 code such as "us" or "de".
+type Region string
+
+// Common languages.
+const (
+	LanguageArabic             Language = "ar"
+	LanguageChineseSimplified  Language = "zh-CN"
+	LanguageChineseTraditional Language = "zh-TW"
+	LanguageDutch              Language = "nl"
+	LanguageEnglish            Language = "en"
+	LanguageEnglishUK          Language = "en-GB"
+	LanguageFrench             Language = "fr"
+	LanguageGerman             Language = "de"
+	LanguageHindi              Language = "hi"
+	LanguageIndonesian         Language = "id"
+	LanguageItalian            Language = "it"
+	LanguageJapanese           Language = "ja"
+	LanguageKorean             Language = "ko"
+	LanguagePolish             Language = "pl"
+	LanguagePortuguese         Language = "pt-PT"
+	LanguagePortugueseBrazil   Language = "pt-BR"
+	LanguageRussian            Language = "ru"
+	LanguageSpanish            Language = "es"
+	LanguageSpanishLatAm       Language = "es-419"
+	LanguageSwedish            Language = "sv"
+	LanguageTurkish            Language = "tr"
+	LanguageUkrainian          Language = "uk"
+)
+
+// Common regions.
+const (
+	RegionAustralia     Region = "au"
+	RegionBrazil        Region = "br"
+	RegionCanada        Region = "ca"
+	RegionFrance        Region = "fr"
+	RegionGermany       Region = "de"
+	RegionIndia         Region = "in"
+	RegionItaly         Region = "it"
+	RegionJapan         Region = "jp"
+	RegionMexico        Region = "mx"
+	RegionNetherlands   Region = "nl"
+	RegionSpain         Region = "es"
+	RegionSouthKorea    Region = "kr"
+	RegionUnitedKingdom Region = "gb"
+	RegionUnitedStates  Region = "us"
+)
+
+// googleLanguages are the primary language subtags Google's hl accepts.
+var googleLanguages = toSet(`af ach ak am ar az be bem bg bh bn br bs ca chr ckb co crs cs cy da de ee
+el en eo es et eu fa fi fo fr fy ga gaa gd gl gn gu ha haw hi hr ht hu hy ia id ig is it iw
+ja jw ka kg kk km kn ko kri ku ky la lg ln lo loz lt lua lv mfe mg mi mk ml mn mo mr ms mt
+ne nl nn no nso ny nyn oc om or pa pcm pl ps pt qu rm rn ro ru rw sd sh si sk sl sn so sq
+sr st su sv sw ta te tg th ti tk tl tn to tr tt tum tw ug uk ur uz vi wo xh yi yo zh zu`)
+
+// languageAliases maps standard codes to the legacy ones Google uses.
+var languageAliases = map[string]string{
+	"he":  "iw",
+	"jv":  "jw",
+	"ji":  "yi",
+	"in":  "id",
+	"nb":  "no",
+	"fil": "tl",
+}
+
+// googleRegions are the ISO 3166-1 alpha-2 codes.
+var googleRegions = toSet(`ad ae af ag ai al am ao aq ar as at au aw ax az ba bb bd be bf bg bh bi bj
+bl bm bn bo bq br bs bt bv bw by bz ca cc cd cf cg ch ci ck cl cm cn co cr cu cv cw cx cy cz
+de dj dk dm do dz ec ee eg eh er es et fi fj fk fm fo fr ga gb gd ge gf gg gh gi gl gm gn gp
+gq gr gs gt gu gw gy hk hm hn hr ht hu id ie il im in io iq ir is it je jm jo jp ke kg kh ki
+km kn kp kr kw ky kz la lb lc li lk lr ls lt lu lv ly ma mc md me mf mg mh mk ml mm mn mo mp
+mq mr ms mt mu mv mw mx my mz na nc ne nf ng ni nl no np nr nu nz om pa pe pf pg ph pk pl pm
+pn pr ps pt pw py qa re ro rs ru rw sa sb sc sd se sg sh si sj sk sl sm sn so sr ss st sv sx
+sy sz tc td tf tg th tj tk tl tm tn to tr tt tv tw tz ua ug um us uy uz va vc ve vg vi vn vu
+wf ws ye yt za zm zw`)
+
+func toSet(fields string) map[string]bool {
+	set := make(map[string]bool)
+	for _, f := range strings.Fields(fields) {
+		set[f] = true
+	}
+	return set
+}
+
+// ParseLanguage validates s as a language tag and returns the form Google
+// accepts. Case and "_" separators are normalized and aliases are mapped,
+// so "zh-Hans" and "zh" become "zh-CN", "zh-Hant-HK" becomes "zh-HK" and
+// "he" becomes "iw".
+func ParseLanguage(s string) (Language, error) {
+	tag := strings.ReplaceAll(strings.TrimSpace(s), "_", "-")
+	if tag == "" {
+		return "", fmt.Errorf("google: empty language")
+	}
+	subtags := strings.Split(strings.ToLower(tag), "-")
+	primary := subtags[0]
+	if alias, ok := languageAliases[primary]; ok {
+		primary = alias
+	}
+	if !googleLanguages[primary] {
+		return "", fmt.Errorf("google: unsupported language %q", s)
+	}
+
+	var script, region string
+	for _, sub := range subtags[1:] {
+		switch {
+		case len(sub) == 4 && script == "" && region == "":
+			script = sub
+		case (len(sub) == 2 || len(sub) == 3 && isDigits(sub)) && region == "":
+			region = sub
+		default:
+			return "", fmt.Errorf("google: unsupported language %q", s)
+		}
+	}
+
+	// Google has no script variants besides Chinese, where they map to
+	// regions; elsewhere the script is dropped.
+	if primary == "zh" {
+		switch {
+		case script == "hant" && (region == "hk" || region == "mo"):
+			region = "hk"
+		case script == "hant":
+			region = "tw"
+		case script == "hans" || region == "" || region == "sg":
+			region = "cn"
+		}
+	}
+	if region == "" {
+		return Language(primary), nil
+	}
+	return Language(primary + "-" + strings.ToUpper(region)), nil
+}
+
+// ParseRegion validates s as a country code and returns it in the lower
+// case form Google's gl expects. "uk" is accepted for "gb".
+func ParseRegion(s string) (Region, error) {
+	code := strings.ToLower(strings.TrimSpace(s))
+	if code == "uk" {
+		code = "gb"
+	}
+	if code == "" {
+		return "", fmt.Errorf("google: empty region")
+	}
+	if !googleRegions[code] {
+		return "", fmt.Errorf("google: unsupported region %q", s)
+	}
+	return Region(code), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
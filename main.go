@@ -1,52 +1,313 @@
 package googlesearch
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/corpix/uarand"
 )
 
+// ErrNoResults is reported to SearchHooks.OnError for pages that did not
+// contain any organic results.
+var ErrNoResults = errors.New("google: page contained no results")
+
+// ErrBlocked is returned when Google answers with a captcha or rate limit
+// page instead of results.
+var ErrBlocked = errors.New("google: request blocked by captcha")
+
+// ErrBandwidthQuota is returned when BandwidthQuota is used up before the
+// first page of a search could be fetched.
+var ErrBandwidthQuota = errors.New("google: bandwidth quota exceeded")
+
+// ErrInsufficientResults is matched by the InsufficientResultsError that
+// StrictCount searches return when they run out of results.
+var ErrInsufficientResults = errors.New("google: insufficient results")
+
+// InsufficientResultsError reports how many of the wanted results a
+// StrictCount search found. The results found are returned along with it.
+type InsufficientResultsError struct {
+	Wanted int
+	Found  int
+}
+
+func (e *InsufficientResultsError) Error() string {
+	return fmt.Sprintf("%s: found %d of %d", ErrInsufficientResults, e.Found, e.Wanted)
+}
+
+func (e *InsufficientResultsError) Unwrap() error {
+	return ErrInsufficientResults
+}
+
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
 type SearchResult struct {
+	// URL is the ASCII form of the result URL (punycode host, percent
+	// encoded path); DisplayURL is the Unicode form browsers display.
 	URL         string
+	DisplayURL  string
 	Title       string
 	Description string
+	// AMPURL and CanonicalURL are set when the result points to an AMP
+	// page; CanonicalURL is the publisher's regular URL for it.
+	AMPURL       string
+	CanonicalURL string
+	// OriginalTitle and OriginalLanguage hold the untranslated title of a
+	// foreign-language result when TranslatedResults is enabled.
+	OriginalTitle    string
+	OriginalLanguage string
+	TranslationURL   string
+	// CachedURL links to Google's cached copy of the page, if offered.
+	CachedURL string
+	// FromExpansion marks results found by following a "More results from"
+	// link (ExpandMoreResults) rather than on the regular results pages.
+	FromExpansion bool
+	// TitleHTML and DescriptionHTML hold the raw inner HTML of the title and
+	// snippet nodes when KeepHTML is enabled.
+	TitleHTML       string
+	DescriptionHTML string
+	// BlockHTML is the serialized result container, set with KeepBlockHTML.
+	BlockHTML string
+	// Category is the kind of page URL points to, see ClassifyURL.
+	Category URLCategory
+	// CachedAt is when the result was fetched if it was served from
+	// Cache, and zero otherwise, see SERPMetadata.Age.
+	CachedAt time.Time `json:",omitzero"`
+	// Data holds the data-* attributes (ved, result ids and the like) found
+	// on the result container and its descendants, keyed without the
+	// "data-" prefix. The first occurrence of a name wins.
+	Data map[string]string
 }
 
 func (sr SearchResult) String() string {
 	return fmt.Sprintf("SearchResult(url=%s, title=%s, description=%s)", sr.URL, sr.Title, sr.Description)
 }
 
+// SearchOptions configures a search. Config holds the same settings in
+// groups and converts to and from SearchOptions.
+type SearchOptions struct {
+	NumResults         int
+	Lang               Language
+	Proxy              string
+	ProxyProvider      ProxyProvider
+	Advanced           bool
+	SleepInterval      int
+	Timeout            int
+	Safe               string
+	InsecureSkipVerify bool
+	Region             Region
+	// Location is either a canonical location name, which is encoded for
+	// the uule parameter, or an already encoded uule value.
+	Location string
+	// Coordinates simulates a device location; it cannot be combined with
+	// Location.
+	Coordinates *Coordinates
+	StartNum    int
+	Unique      bool
+	// TranslatedResults asks Google to include foreign-language pages
+	// translated into Lang (tbs=clir:1).
+	TranslatedResults bool
+	// ExtraParams are merged into the request query string, replacing any
+	// value the library sets for the same key.
+	ExtraParams url.Values
+	// FeatureSummaryOnly skips parsing the contents of answer boxes and
+	// SERP features; only SERPMetadata.Features is filled.
+	FeatureSummaryOnly bool
+	Hooks              *SearchHooks
+	// DisableProfileRetry turns off the single retry with an alternate
+	// user agent and layout when a page parses to zero results.
+	DisableProfileRetry bool
+	// RotateUserAgent picks a new user agent for every page request. By
+	// default one user agent and cookie jar are kept for the whole search.
+	RotateUserAgent bool
+	// UserAgentPages, if set, starts a fresh session with a new user agent
+	// and cookies every UserAgentPages pages, whatever Pacing does.
+	UserAgentPages int
+	// Pacing replaces SleepInterval with jittered delays and periodic
+	// session cooldowns, see PacingProfile.
+	Pacing *PacingProfile
+	// Throttle, if set, stretches the delays between page requests after
+	// captchas and 429s and eases off again after sustained success.
+	Throttle *AdaptiveThrottle
+	// Retries is how often a page request that failed with a network
+	// error, a 5xx status or a block is retried. The first retry waits
+	// RetryBackoff (default 5s), every further one twice as long.
+	Retries      int
+	RetryBackoff time.Duration
+	// WarmUp visits the Google homepage before the first search of a
+	// session to pick up fresh cookies. WarmUpQuery, if set, is searched as
+	// well and its results discarded.
+	WarmUp      bool
+	WarmUpQuery string
+	// DisableWarmUp turns WarmUp off, including the warm-up
+	// NewPoliteSearcher enables.
+	DisableWarmUp bool
+	// SessionFile, if set, is where a Searcher keeps its session across
+	// processes: NewSearcher restores it when the file exists and Close
+	// saves it. Profiles use the file name suffixed with their name.
+	// Package level searches ignore it.
+	SessionFile string
+	// Labels are echoed on every SearchResponse and record of the search,
+	// see WithLabels for setting them per call.
+	Labels Labels
+	// BlockRegistry, if set, records proxies that were served captchas and
+	// skips them until their cooldown has passed.
+	BlockRegistry *BlockRegistry
+	// HARRecorder, if set, records all requests and responses.
+	HARRecorder *HARRecorder
+	// AuditLog, if set, logs every outbound request.
+	AuditLog *AuditLog
+	// KeepNonWebLinks keeps javascript:, mailto:, tel: and Google-internal
+	// navigation links (Maps, Translate, accounts) that are dropped from the
+	// organic results by default.
+	KeepNonWebLinks bool
+	// ExpandMoreResults follows "More results from example.com" links and
+	// adds the additional same-site results to the stream.
+	ExpandMoreResults bool
+	// AllResults paginates until Google stops returning new results,
+	// ignoring NumResults. A negative NumResults has the same effect.
+	AllResults bool
+	// MaxPages caps the number of results pages fetched; zero means no cap.
+	MaxPages int
+	// MinNewResultsPerPage stops pagination after a page that delivered
+	// fewer new results than this; by default only a page without any new
+	// results ends the search.
+	MinNewResultsPerPage int
+	// StopOnEmptyPage ends the search at the first empty follow-up page
+	// instead of re-requesting it with the alternate fetch profile.
+	StopOnEmptyPage bool
+	// StrictCount keeps paginating, within MaxPages, until exactly
+	// NumResults unique results are delivered and otherwise fails with an
+	// InsufficientResultsError. It implies Unique and ignores
+	// MinNewResultsPerPage.
+	StrictCount bool
+	// FuzzyDedup additionally drops results whose normalized title and
+	// registrable domain match an earlier result, on top of URL dedup.
+	FuzzyDedup bool
+	// KeepHTML attaches the raw inner HTML of each result's title and
+	// snippet to TitleHTML and DescriptionHTML.
+	KeepHTML bool
+	// KeepBlockHTML attaches the outer HTML of each result's container node
+	// to BlockHTML, for fields this package does not model.
+	KeepBlockHTML bool
+	// Extractor replaces the built-in organic result extraction.
+	Extractor ResultExtractor
+	// Normalize cleans up result titles and descriptions.
+	Normalize TextNormalization
+	// DisableCoalescing stops a Searcher from sharing one fetch between
+	// identical concurrent queries.
+	DisableCoalescing bool
+	// TimeRange limits results to recently indexed pages.
+	TimeRange TimeRange
+	// Cache, if set, stores finished searches and serves repeats of the
+	// same query and options from it for CacheTTL (default 24h).
+	Cache    Cache
+	CacheTTL time.Duration
+	// CacheMaxAge, if set, only serves cached results younger than it and
+	// refetches older ones, so entries can be kept for CacheTTL while
+	// monitoring refreshes them more often. SERPMetadata.CachedAt tells
+	// how old served results are.
+	CacheMaxAge time.Duration
+	// ConsentHandler completes Google's cookie consent page when a search
+	// is redirected there; nil uses DefaultConsentHandler. Set
+	// DisableConsentRecovery to fail with ErrConsent instead.
+	ConsentHandler         ConsentHandler
+	DisableConsentRecovery bool
+	// AlternateDomains are Google domains such as "google.ca" to retry a
+	// page on, in order, when google.com answers with a captcha or 429.
+	// Unless Region is set, gl is adjusted to the domain's country.
+	AlternateDomains []string
+	// SafeSearch selects the explicit content filter, translated to the
+	// safe= value of the vertical being searched. While it is
+	// SafeSearchDefault the legacy Safe string ("active", "images", "off")
+	// is parsed instead.
+	SafeSearch SafeSearch
+	// ReRank, if set, reorders results before they are returned; see
+	// ReRankBy for composing scorers. It is applied to the whole result
+	// list, or to every page before delivery when ReRankPerPage is set,
+	// which is the only mode streaming searches support.
+	ReRank        func([]SearchResult) []SearchResult
+	ReRankPerPage bool
+	// DomainSummary fills SERPMetadata.Domains with AggregateByDomain of
+	// the delivered results once the search ends.
+	DomainSummary bool
+	// Archive, if set, stores the raw HTML of every results page fetched.
+	Archive *Archiver
+	// Journal, if set, persists the delivered results page by page while
+	// the search runs.
+	Journal *ResultJournal
+	// BandwidthQuota stops pagination once the session has downloaded
+	// this many bytes, counted compressed as they come over the wire. For
+	// package level functions that is per search; a Searcher counts across
+	// all its searches, see Searcher.BytesDownloaded.
+	BandwidthQuota int64
+	// UserAgent replaces the randomly picked browser user agent. The
+	// fallback profile used for empty pages keeps its own.
+	UserAgent string
+	// Domain is the Google domain searched, such as "google.de"; it
+	// defaults to google.com.
+	Domain string
+	// Preset names a built-in device and locale emulation, see
+	// PresetByName. It fills UserAgent, Lang, Region and Domain where they
+	// are not set explicitly.
+	Preset string
+	// DisablePersonalization sends pws=0 so results do not depend on
+	// the search history of the session's cookies.
+	DisablePersonalization bool
+
+	// profile is the name of the Searcher profile, see Searcher.Profile.
+	profile string
+}
+
+// limit is the number of results a search delivers at most.
+func (o SearchOptions) limit() int {
+	if o.AllResults || o.NumResults < 0 {
+		return math.MaxInt
+	}
+	return o.NumResults
+}
+
+// pageSize is the num parameter sent with each request.
+func (o SearchOptions) pageSize() int {
+	if limit := o.limit(); limit < 98 {
+		return limit + 2
+	}
+	return 100
+}
 
 func GetCustomUserAgent() string {
-	lynx := fmt.Sprintf("Lynx/%d.%d.%d", 
+	lynx := fmt.Sprintf("Lynx/%d.%d.%d",
 		rand.Intn(2)+2,
 		rand.Intn(2)+8,
 		rand.Intn(3))
-	
-	libwww := fmt.Sprintf("libwww-FM/%d.%d", 
+
+	libwww := fmt.Sprintf("libwww-FM/%d.%d",
 		rand.Intn(2)+2,
 		rand.Intn(3)+13)
-	
-	sslmm := fmt.Sprintf("SSL-MM/%d.%d", 
+
+	sslmm := fmt.Sprintf("SSL-MM/%d.%d",
 		rand.Intn(2)+1,
 		rand.Intn(3)+3)
-	
-	openssl := fmt.Sprintf("OpenSSL/%d.%d.%d", 
+
+	openssl := fmt.Sprintf("OpenSSL/%d.%d.%d",
 		rand.Intn(3)+1,
 		rand.Intn(5),
 		rand.Intn(10))
-	
+
 	return fmt.Sprintf("%s %s %s %s", lynx, libwww, sslmm, openssl)
 }
 
@@ -54,31 +315,308 @@ func getRandomUserAgent() string {
 	return uarand.GetRandom()
 }
 
-func sendRequest(term string, results int, lang string, start int, client *http.Client, region, safe string, sslVerify bool) (*http.Response, error) {
-	baseURL := "https://www.google.com/search"
-	req, err := http.NewRequest("GET", baseURL, nil)
+// newClient returns the HTTP client for a session. Response bytes are
+// added to downloaded unless it is nil.
+func newClient(opts SearchOptions, downloaded *atomic.Int64) *http.Client {
+	var transport http.RoundTripper = &http.Transport{
+		Proxy: proxyFromContext,
+	}
+	if opts.InsecureSkipVerify {
+		transport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	if downloaded != nil {
+		transport = &meterTransport{next: transport, total: downloaded}
+	}
+
+	client := &http.Client{
+		Timeout:   time.Duration(opts.Timeout) * time.Second,
+		Transport: transport,
+	}
+	if opts.HARRecorder != nil {
+		client.Transport = &harTransport{next: transport, recorder: opts.HARRecorder}
+	}
+	if opts.AuditLog != nil {
+		client.Transport = &auditTransport{next: client.Transport, log: opts.AuditLog, labels: opts.Labels}
+	}
+	if !opts.RotateUserAgent {
+		client.Jar = newRecordingJar()
+	}
+	return client
+}
+
+func (s *session) sendRequest(ctx context.Context, term string, start int, profile fetchProfile) (*http.Response, error) {
+	opts := s.opts
+	domain := searchDomain(ctx)
+	baseURL := "https://www." + domain + "/search"
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	q := req.URL.Query()
 	q.Add("q", term)
-	q.Add("num", fmt.Sprintf("%d", results+2))
-	q.Add("hl", lang)
+	q.Add("num", fmt.Sprintf("%d", opts.pageSize()))
+	q.Add("hl", string(opts.Lang))
 	q.Add("start", fmt.Sprintf("%d", start))
-	q.Add("safe", safe)
-	if region != "" {
-		q.Add("gl", region)
+	q.Add("safe", opts.SafeSearch.param(opts.ExtraParams.Get("tbm")))
+	if opts.Region != "" {
+		q.Add("gl", string(opts.Region))
+	} else if gl := domainCountry(domain); gl != "" {
+		q.Add("gl", gl)
+	}
+	if opts.Location != "" {
+		q.Add("uule", opts.Location)
+	}
+	var tbs []string
+	if opts.TranslatedResults {
+		tbs = append(tbs, "clir:1")
+	}
+	if qdr := opts.TimeRange.tbs(); qdr != "" {
+		tbs = append(tbs, qdr)
+	}
+	if len(tbs) > 0 {
+		q.Add("tbs", strings.Join(tbs, ","))
+	}
+	if opts.DisablePersonalization {
+		q.Add("pws", "0")
+	}
+	for key, values := range profile.params {
+		q[key] = append([]string(nil), values...)
+	}
+	for key, values := range opts.ExtraParams {
+		q[key] = append([]string(nil), values...)
 	}
 	req.URL.RawQuery = q.Encode()
 
-	req.Header.Set("User-Agent", getRandomUserAgent())
-	req.Header.Set("Accept", "*/*")
+	setBrowserHeaders(req.Header, s.userAgent(profile), string(opts.Lang), string(opts.Region), s.referer(profile))
+
+	s.addConsentCookies(req)
+
+	resp, err := s.httpClient().Do(req)
+	if err == nil {
+		s.setReferer(profile, req.URL.String())
+	}
+	return resp, err
+}
 
-	req.AddCookie(&http.Cookie{Name: "CONSENT", Value: "PENDING+987"})
-	req.AddCookie(&http.Cookie{Name: "SOCS", Value: "CAESHAgBEhIaAB"})
+// fetchBody sends the search request and reads the (size limited) body.
+func (s *session) fetchBody(ctx context.Context, term string, start int, profile fetchProfile) (*http.Response, []byte, error) {
+	resp, err := s.sendRequest(ctx, term, start, profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
 
-	return client.Do(req)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return nil, nil, err
+	}
+	s.opts.Archive.archive(ctx, term, start, body)
+	return resp, body, nil
+}
+
+type serpPage struct {
+	results  []SearchResult
+	metadata SERPMetadata
+	// resp and body are the response the page was parsed from.
+	resp *http.Response
+	body []byte
+	// emptyErr carries the DebugInfo of a page without results.
+	emptyErr error
+	// moreResultsTerms are the searches behind "More results from" links.
+	moreResultsTerms []string
+}
+
+// defaultRetryBackoff is the wait before the first retry when RetryBackoff
+// is zero.
+const defaultRetryBackoff = 5 * time.Second
+
+// fetchPage is fetchPageOnce with the retries of opts.Retries.
+func (s *session) fetchPage(ctx context.Context, term string, start int) (*serpPage, error) {
+	backoff := s.opts.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		page, err := s.fetchPageOnce(ctx, term, start)
+		if err == nil || attempt >= s.opts.Retries || !retryable(err) || ctx.Err() != nil {
+			return page, err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return page, err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether a failed page request may succeed when tried
+// again later.
+func retryable(err error) bool {
+	if errors.Is(err, ErrBlocked) {
+		return true
+	}
+	var debugErr *DebugError
+	if errors.As(err, &debugErr) && debugErr.Debug != nil {
+		return debugErr.Debug.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// fetchPageOnce requests a single results page through the configured
+// proxy provider and reports the outcome back to it.
+func (s *session) fetchPageOnce(ctx context.Context, term string, start int) (*serpPage, error) {
+	proxy, release, err := s.nextProxy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = withProxy(s.opts.domainContext(ctx), proxy)
+
+	page, err := s.fetchResults(ctx, term, start, defaultProfile)
+	s.pace.done()
+	for _, domain := range s.opts.AlternateDomains {
+		if !errors.Is(err, ErrBlocked) || ctx.Err() != nil {
+			break
+		}
+		ctx = withDomain(ctx, normalizeGoogleDomain(domain))
+		page, err = s.fetchResults(ctx, term, start, defaultProfile)
+		s.pace.done()
+	}
+	// An empty page is tried once more with the alternate profile, in the
+	// same session. Only a page with results replaces it; a failed retry
+	// must not turn a valid empty page into an error.
+	retry := !s.opts.DisableProfileRetry && !(s.opts.StopOnEmptyPage && start != s.opts.StartNum)
+	if errors.Is(err, ErrNoResults) && retry && s.pace.pause(ctx) == nil {
+		retryPage, retryErr := s.fetchResults(ctx, term, start, defaultProfile.alternate())
+		s.pace.done()
+		if retryErr == nil {
+			page, err = retryPage, nil
+		}
+	}
+	if release != nil {
+		release(err == nil || errors.Is(err, ErrNoResults))
+	}
+	s.reportBlock(ctx, proxy, err)
+	s.pace.observe(err)
+	if errors.Is(err, ErrNoResults) {
+		return page, nil
+	}
+	return page, err
+}
+
+func (s *session) fetchResults(ctx context.Context, term string, start int, profile fetchProfile) (*serpPage, error) {
+	resp, body, err := s.fetchBody(ctx, term, start, profile)
+	if err != nil {
+		return nil, err
+	}
+	if isConsentPage(resp) {
+		if s.opts.DisableConsentRecovery {
+			return nil, newDebugError(ErrConsent, resp, body, nil)
+		}
+		if err := s.completeConsent(ctx, resp, body); err != nil {
+			return nil, newDebugError(err, resp, body, nil)
+		}
+		if resp, body, err = s.fetchBody(ctx, term, start, profile); err != nil {
+			return nil, err
+		}
+		if isConsentPage(resp) {
+			return nil, newDebugError(ErrConsent, resp, body, nil)
+		}
+	}
+
+	if isBlocked(resp, nil) {
+		return nil, newDebugError(ErrBlocked, resp, body, nil)
+	}
+	if resp.StatusCode != 200 {
+		return nil, newDebugError(fmt.Errorf("google: received non-200 status code: %d", resp.StatusCode), resp, body, nil)
+	}
+
+	doc, err := newDocument(bytes.NewReader(body))
+	if err != nil {
+		return nil, newDebugError(err, resp, body, nil)
+	}
+	// Relative links resolve against the Google domain actually searched.
+	doc.Url = resp.Request.URL
+	if isBlocked(resp, doc) {
+		return nil, newDebugError(ErrBlocked, resp, body, doc)
+	}
+
+	page, err := parseDocument(doc, s.opts)
+	if err != nil {
+		return nil, newDebugError(err, resp, body, doc)
+	}
+	page.resp, page.body = resp, body
+	if len(page.results) == 0 {
+		page.emptyErr = newDebugError(ErrNoResults, resp, body, doc)
+		return page, page.emptyErr
+	}
+	return page, nil
+}
+
+func extractResults(doc *goquery.Document, opts SearchOptions) []SearchResult {
+	var results []SearchResult
+	selectors := CurrentSelectors()
+	doc.Find(selectors.Result).Each(func(i int, s *goquery.Selection) {
+		if result, ok := extractResult(s, doc.Url, selectors, opts); ok {
+			results = append(results, result)
+		}
+	})
+	return results
+}
+
+// extractResult reads one result block; base is the URL of the results
+// page, for resolving relative links, and may be nil.
+func extractResult(s *goquery.Selection, base *url.URL, selectors SelectorBundle, opts SearchOptions) (SearchResult, bool) {
+	linkTag := s.Find(selectors.Link).First()
+	href, exists := linkTag.Attr("href")
+	if !exists {
+		return SearchResult{}, false
+	}
+
+	if !strings.HasPrefix(href, "/url?q=") {
+		return SearchResult{}, false
+	}
+	params, err := url.ParseQuery(strings.TrimPrefix(href, "/url?"))
+	if err != nil {
+		return SearchResult{}, false
+	}
+	target := params.Get("q")
+	link, displayLink, ok := normalizeResultURL(target)
+	if !ok {
+		// Non-web links such as mailto: have no host; keep them verbatim
+		// so the KeepNonWebLinks option can decide about them.
+		if u, err := url.Parse(target); err != nil || u.Scheme == "" {
+			return SearchResult{}, false
+		}
+		link, displayLink = target, target
+	}
+
+	titleNode := linkTag.Find(selectors.Title).First()
+	descriptionNode := s.Find(selectors.Description).First()
+	result := SearchResult{
+		URL:         link,
+		DisplayURL:  displayLink,
+		Title:       cleanText(titleNode.Text()),
+		Description: cleanText(descriptionNode.Text()),
+	}
+	if opts.KeepHTML {
+		result.TitleHTML = innerHTML(titleNode)
+		result.DescriptionHTML = innerHTML(descriptionNode)
+	}
+	result.Data = dataAttributes(s)
+	if opts.KeepBlockHTML {
+		if h, err := goquery.OuterHtml(s); err == nil {
+			result.BlockHTML = cleanText(h)
+		}
+	}
+	detectAMP(&result, linkTag)
+	extractTranslation(&result, s, base)
+	result.CachedURL = extractCachedURL(s, base)
+	return result, true
 }
 
 func Search(
@@ -95,107 +633,321 @@ func Search(
 	startNum int,
 	unique bool,
 ) ([]interface{}, error) {
-	client := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
+	return SearchWithOptions(context.Background(), term, SearchOptions{
+		NumResults:         numResults,
+		Lang:               Language(lang),
+		Proxy:              proxy,
+		Advanced:           advanced,
+		SleepInterval:      sleepInterval,
+		Timeout:            timeout,
+		Safe:               safe,
+		InsecureSkipVerify: !sslVerify,
+		Region:             Region(region),
+		StartNum:           startNum,
+		Unique:             unique,
+	})
+}
+
+func SearchWithOptions(ctx context.Context, term string, opts SearchOptions) ([]interface{}, error) {
+	found, _, err := search(ctx, term, opts)
+	if err != nil && len(found) == 0 {
+		return nil, err
 	}
 
-	if proxy != "" {
-		proxyURL, err := url.Parse(proxy)
+	var results []interface{}
+	for _, result := range found {
+		if opts.Advanced {
+			results = append(results, result)
+		} else {
+			results = append(results, result.URL)
+		}
+	}
+	return results, err
+}
+
+// SearchWithMetadata behaves like SearchWithOptions but always returns typed
+// results together with the answer boxes found on the result pages.
+func SearchWithMetadata(ctx context.Context, term string, opts SearchOptions) ([]SearchResult, *SERPMetadata, error) {
+	return search(ctx, term, opts)
+}
+
+func search(ctx context.Context, term string, opts SearchOptions) ([]SearchResult, *SERPMetadata, error) {
+	opts, err := prepareOptions(opts)
+	if err != nil {
+		return nil, &SERPMetadata{}, err
+	}
+	return searchPrepared(ctx, term, opts)
+}
+
+// searchPrepared is search with options already prepared.
+func searchPrepared(ctx context.Context, term string, opts SearchOptions) ([]SearchResult, *SERPMetadata, error) {
+	results, metadata, err := cachedSearch(ctx, term, opts, func() ([]SearchResult, *SERPMetadata, error) {
+		var results []SearchResult
+		metadata, err := walkSession(ctx, newSession(opts), term, func(resp SearchResponse) error {
+			results = append(results, resp.Result)
+			return nil
+		})
+		return results, metadata, err
+	})
+	return reRank(results, opts), metadata, err
+}
+
+// reRank applies a per-search ReRank to the collected results.
+func reRank(results []SearchResult, opts SearchOptions) []SearchResult {
+	if opts.ReRank == nil || opts.ReRankPerPage || len(results) == 0 {
+		return results
+	}
+	return opts.ReRank(results)
+}
+
+// prepareOptions fills defaults and resolves derived settings shared by
+// every request the package sends.
+func prepareOptions(opts SearchOptions) (SearchOptions, error) {
+	if opts.Preset != "" {
+		preset, err := PresetByName(opts.Preset)
 		if err != nil {
-			return nil, err
+			return opts, err
+		}
+		opts = preset.apply(opts)
+	}
+	opts.Domain = normalizeGoogleDomain(opts.Domain)
+
+	if opts.Lang != "" {
+		lang, err := ParseLanguage(string(opts.Lang))
+		if err != nil {
+			return opts, err
+		}
+		opts.Lang = lang
+	}
+	if opts.Region != "" {
+		region, err := ParseRegion(string(opts.Region))
+		if err != nil {
+			return opts, err
 		}
-		client.Transport = &http.Transport{
-			Proxy: http.ProxyURL(proxyURL),
+		opts.Region = region
+	}
+	timeRange, err := ParseTimeRange(string(opts.TimeRange))
+	if err != nil {
+		return opts, err
+	}
+	opts.TimeRange = timeRange
+
+	if opts.SafeSearch == SafeSearchDefault {
+		safe, err := ParseSafeSearch(opts.Safe)
+		if err != nil {
+			return opts, err
 		}
+		opts.SafeSearch = safe
+	}
+
+	uule, err := resolveUULE(opts.Location, opts.Coordinates)
+	if err != nil {
+		return opts, err
+	}
+	opts.Location = uule
+
+	if opts.StrictCount {
+		opts.Unique = true
+	}
+	if opts.DisableWarmUp {
+		opts.WarmUp = false
 	}
 
-	if !sslVerify {
-		client.Transport = &http.Transport{
-			Proxy:           client.Transport.(*http.Transport).Proxy,
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	if opts.ProxyProvider == nil {
+		provider, err := NewStaticProxyProvider(opts.Proxy)
+		if err != nil {
+			return opts, err
 		}
+		opts.ProxyProvider = provider
+	}
+	return opts, nil
+}
+
+// walk paginates through the results for term and calls fn for every
+// accepted result until NumResults are delivered, Google runs out of
+// results or fn returns an error, which walk then returns.
+func walk(ctx context.Context, term string, opts SearchOptions, fn func(SearchResponse) error) (*SERPMetadata, error) {
+	opts, err := prepareOptions(opts)
+	if err != nil {
+		return &SERPMetadata{}, err
+	}
+	return walkSession(ctx, newSession(opts), term, fn)
+}
+
+// walkSession is walk on an existing session, whose options must already
+// have been prepared.
+func walkSession(ctx context.Context, sess *session, term string, fn func(SearchResponse) error) (*SERPMetadata, error) {
+	term, err := SanitizeQuery(term)
+	if err != nil {
+		return &SERPMetadata{}, err
 	}
 
-	if safe == "" {
-		safe = "active"
+	journal := sess.opts.Journal.begin(ctx, term, searchLabels(ctx, sess.opts))
+	if journal != nil {
+		deliver := fn
+		// Only results the consumer took are journaled as delivered.
+		fn = func(resp SearchResponse) error {
+			if err := deliver(resp); err != nil {
+				return err
+			}
+			journal.add(resp)
+			return nil
+		}
 	}
+	var downloaded atomic.Int64
+	metadata, err := walkPages(withByteMeter(ctx, &downloaded), sess, term, fn, journal)
+	metadata.BytesDownloaded = downloaded.Load()
+	journal.finish(err)
+	return metadata, err
+}
+
+// walkPages does the pagination of walkSession. journal, if not nil, is
+// flushed after every page.
+func walkPages(ctx context.Context, sess *session, term string, fn func(SearchResponse) error, journal *journalRun) (*SERPMetadata, error) {
+	opts := sess.opts
+	metadata := &SERPMetadata{}
+	labels := searchLabels(ctx, opts)
 
-	start := startNum
-	fetchedResults := 0
+	if opts.DomainSummary {
+		var collected []SearchResult
+		deliver := fn
+		fn = func(resp SearchResponse) error {
+			collected = append(collected, resp.Result)
+			return deliver(resp)
+		}
+		defer func() { metadata.Domains = AggregateByDomain(collected) }()
+	}
+
+	start := opts.StartNum
 	fetchedLinks := make(map[string]bool)
-	var results []interface{}
+	fuzzyKeys := make(map[string]bool)
+	// previousPage holds the URLs of the last page. Google does not always
+	// honour num, so consecutive pages can overlap; those repeats are
+	// skipped even when Unique is off.
+	var previousPage map[string]bool
+	delivered := 0
 
-	for fetchedResults < numResults {
-		resp, err := sendRequest(term, numResults, lang, start, client, region, safe, sslVerify)
-		if err != nil {
-			return nil, err
+	limit := opts.limit()
+	for pageNum := 1; delivered < limit; pageNum++ {
+		if opts.MaxPages > 0 && pageNum > opts.MaxPages {
+			break
+		}
+		if opts.BandwidthQuota > 0 && sess.downloaded.Load() >= opts.BandwidthQuota {
+			metadata.BandwidthExceeded = true
+			if pageNum == 1 {
+				return metadata, ErrBandwidthQuota
+			}
+			break
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != 200 {
-			return nil, fmt.Errorf("google: received non-200 status code: %d", resp.StatusCode)
+		if err := sess.prepare(ctx); err != nil {
+			opts.Hooks.error(pageNum, err)
+			return metadata, err
 		}
 
-		doc, err := goquery.NewDocumentFromReader(resp.Body)
+		page, err := sess.fetchPage(withPageNum(ctx, pageNum), term, start)
 		if err != nil {
-			return nil, err
+			opts.Hooks.error(pageNum, err)
+			return metadata, err
+		}
+		metadata.merge(page.metadata)
+		metadata.PagesFetched++
+		if len(page.results) == 0 {
+			opts.Hooks.error(pageNum, page.emptyErr)
 		}
 
-		newResults := 0
-		doc.Find("div.ezO2md").Each(func(i int, s *goquery.Selection) {
-			if fetchedResults >= numResults {
-				return
+		// serpIndex maps results back to their position on the page, so
+		// IndexOnPage and Rank keep describing Google's placement after a
+		// per-page ReRank.
+		var serpIndex map[string]int
+		if opts.ReRank != nil && opts.ReRankPerPage {
+			serpIndex = make(map[string]int, len(page.results))
+			for i, result := range page.results {
+				if _, ok := serpIndex[result.URL]; !ok {
+					serpIndex[result.URL] = i
+				}
 			}
+			page.results = opts.ReRank(page.results)
+		}
 
-			linkTag := s.Find("a[href]").First()
-			href, exists := linkTag.Attr("href")
-			if !exists {
-				return
+		newResults, overlapping := 0, 0
+		currentPage := make(map[string]bool, len(page.results))
+		for _, result := range page.results {
+			currentPage[result.URL] = true
+		}
+		for i, result := range page.results {
+			if delivered >= limit {
+				break
 			}
 
-			if !strings.HasPrefix(href, "/url?q=") {
-				return
+			if previousPage[result.URL] {
+				overlapping++
+				continue
 			}
-			link := strings.TrimPrefix(href, "/url?q=")
-			if idx := strings.Index(link, "&"); idx != -1 {
-				link = link[:idx]
+			if opts.Unique && fetchedLinks[result.URL] {
+				continue
 			}
-			decodedLink, err := url.QueryUnescape(link)
-			if err != nil || decodedLink == "" {
-				return
+			fetchedLinks[result.URL] = true
+			if opts.FuzzyDedup {
+				if key := fuzzyKey(result); key != "" {
+					if fuzzyKeys[key] {
+						continue
+					}
+					fuzzyKeys[key] = true
+				}
 			}
 
-			if unique && fetchedLinks[decodedLink] {
-				return
+			if idx, ok := serpIndex[result.URL]; ok {
+				i = idx
+			}
+			delivered++
+			newResults++
+			resp := SearchResponse{
+				Result:      result,
+				Page:        pageNum,
+				IndexOnPage: i + 1,
+				Rank:        start - opts.StartNum + i + 1,
+				Labels:      labels,
 			}
-			fetchedLinks[decodedLink] = true
-
-			title := linkTag.Find("span.CVA68e").First().Text()
-			description := s.Find("span.FrIlee").First().Text()
-
-			if advanced {
-				results = append(results, SearchResult{
-					URL:         decodedLink,
-					Title:       title,
-					Description: description,
-				})
-			} else {
-				results = append(results, decodedLink)
+			opts.Hooks.result(resp)
+			if err := fn(resp); err != nil {
+				return metadata, err
 			}
+		}
 
-			fetchedResults++
-			newResults++
+		if opts.ExpandMoreResults && delivered < limit {
+			expanded, err := expandMoreResults(ctx, sess, page, pageNum, limit-delivered, fetchedLinks, fn)
+			delivered += expanded
+			newResults += expanded
+			if err != nil {
+				opts.Hooks.error(pageNum, err)
+				return metadata, err
+			}
+		}
+
+		opts.Hooks.pageComplete(PageInfo{
+			Page:        pageNum,
+			Start:       start,
+			Results:     len(page.results),
+			NewResults:  newResults,
+			Overlapping: overlapping,
+			Labels:      labels,
 		})
+		journal.flush()
 
-		if newResults == 0 {
+		if newResults == 0 || (!opts.StrictCount && newResults < opts.MinNewResultsPerPage) {
+			metadata.Exhausted = true
 			break
 		}
 
-		start += 10
-		if sleepInterval > 0 {
-			time.Sleep(time.Duration(sleepInterval) * time.Second)
-		}
+		// Continue after the results actually returned rather than a fixed
+		// 10, which overlapped whenever num was honoured.
+		start += len(page.results)
+		previousPage = currentPage
 	}
 
-	return results, nil
+	if opts.StrictCount && delivered < limit && limit != math.MaxInt {
+		return metadata, &InsufficientResultsError{Wanted: limit, Found: delivered}
+	}
+	return metadata, nil
 }
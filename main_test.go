@@ -0,0 +1,72 @@
+package googlesearch
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProfileRetryFailureKeepsEmptyPage(t *testing.T) {
+	empty := servePage(t, "noresults")
+	var google *fakeGoogle
+	google = newFakeGoogleFunc(t, func(w http.ResponseWriter, r *http.Request) {
+		// The first request gets an empty page, the profile retry a
+		// server error.
+		if google.searches.Load() > 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		empty(w, r)
+	})
+	proxy := newFakeProxy(t, google)
+	s, err := NewSearcher(SearchOptions{NumResults: 10, Proxy: proxy.URL, InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := s.Search(context.Background(), "no such thing")
+	if err != nil {
+		t.Fatalf("Search failed with the retry's error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("got %d results, want none", len(results))
+	}
+	if got := google.searches.Load(); got != 2 {
+		t.Errorf("google saw %d searches, want the first and the profile retry", got)
+	}
+}
+
+func TestProfileRetryKeepsSession(t *testing.T) {
+	empty := servePage(t, "noresults")
+	var warmUps atomic.Int64
+	google := newFakeGoogleFunc(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			warmUps.Add(1)
+		}
+		empty(w, r)
+	})
+	proxy := newFakeProxy(t, google)
+	// Every page uses up the session, so a retry that went through
+	// prepare would rotate it and warm the new one up.
+	pacing := PacingProfile{MinDelay: time.Millisecond, MaxDelay: time.Millisecond, PagesPerSession: 1}
+	s, err := NewSearcher(SearchOptions{
+		NumResults:         10,
+		Proxy:              proxy.URL,
+		InsecureSkipVerify: true,
+		Pacing:             &pacing,
+		WarmUp:             true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Search(context.Background(), "no such thing"); err != nil {
+		t.Fatal(err)
+	}
+	if got := google.searches.Load(); got != 2 {
+		t.Errorf("google saw %d searches, want the first and the profile retry", got)
+	}
+	if got := warmUps.Load(); got != 1 {
+		t.Errorf("the session was warmed up %d times, want once", got)
+	}
+}
@@ -0,0 +1,111 @@
+package googlesearch
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TermMatch lists where a query term occurs in a text. Positions are rune
+// offsets of each occurrence.
+type TermMatch struct {
+	Term      string
+	Positions []int
+}
+
+// MatchReport describes which query terms a result matched.
+type MatchReport struct {
+	Result  SearchResult
+	Title   []TermMatch
+	Snippet []TermMatch
+	// Missing lists terms found in neither title nor snippet.
+	Missing []string
+	// Coverage is the fraction of query terms found anywhere.
+	Coverage float64
+}
+
+// AnalyzeMatches annotates each result with the terms of query that occur
+// in its title and description. Matching ignores case and only counts
+// occurrences starting at a word boundary. Quoted phrases count as one
+// term; operators such as site: and excluded -terms are ignored.
+func AnalyzeMatches(query string, results []SearchResult) []MatchReport {
+	terms := queryTerms(query)
+	reports := make([]MatchReport, len(results))
+	for i, result := range results {
+		report := MatchReport{Result: result}
+		found := 0
+		for _, term := range terms {
+			inTitle := findTerm(result.Title, term)
+			inSnippet := findTerm(result.Description, term)
+			if inTitle != nil {
+				report.Title = append(report.Title, TermMatch{Term: term, Positions: inTitle})
+			}
+			if inSnippet != nil {
+				report.Snippet = append(report.Snippet, TermMatch{Term: term, Positions: inSnippet})
+			}
+			if inTitle == nil && inSnippet == nil {
+				report.Missing = append(report.Missing, term)
+			} else {
+				found++
+			}
+		}
+		if len(terms) > 0 {
+			report.Coverage = float64(found) / float64(len(terms))
+		}
+		reports[i] = report
+	}
+	return reports
+}
+
+// queryTerms splits query into lowercase terms and quoted phrases.
+func queryTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	add := func(term string) {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term != "" && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	for query != "" {
+		query = strings.TrimLeft(query, " \t")
+		if strings.HasPrefix(query, `"`) {
+			end := strings.Index(query[1:], `"`)
+			if end < 0 {
+				add(query[1:])
+				break
+			}
+			add(query[1 : end+1])
+			query = query[end+2:]
+			continue
+		}
+		word := query
+		if i := strings.IndexAny(query, " \t"); i >= 0 {
+			word, query = query[:i], query[i:]
+		} else {
+			query = ""
+		}
+		if strings.HasPrefix(word, "-") || strings.Contains(word, ":") {
+			continue
+		}
+		add(strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }))
+	}
+	return terms
+}
+
+// findTerm returns the rune offsets at which term starts a word in text.
+func findTerm(text, term string) []int {
+	haystack := []rune(strings.ToLower(text))
+	needle := []rune(term)
+	var positions []int
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		if i > 0 && (unicode.IsLetter(haystack[i-1]) || unicode.IsDigit(haystack[i-1])) {
+			continue
+		}
+		if string(haystack[i:i+len(needle)]) == term {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
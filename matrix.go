@@ -0,0 +1,114 @@
+package googlesearch
+
+import (
+	"context"
+	"sync"
+)
+
+// matrixConcurrency is the number of locales MatrixSearch runs at once.
+const matrixConcurrency = 4
+
+// Locale is one hl/gl combination of a MatrixSearch.
+type Locale struct {
+	Lang   Language
+	Region Region
+}
+
+func (l Locale) String() string {
+	if l.Region == "" {
+		return string(l.Lang)
+	}
+	return string(l.Lang) + "-" + string(l.Region)
+}
+
+// LocaleResults is the outcome of a MatrixSearch for one locale.
+type LocaleResults struct {
+	Results  []SearchResult
+	Metadata *SERPMetadata
+	Err      error
+}
+
+// MatrixSearch runs query for every combination of langs and regions
+// concurrently and returns the outcomes keyed by locale, for comparing
+// rankings across markets. An empty langs or regions uses opts.Lang or
+// opts.Region. Every locale gets its own session, but all of them share
+// one pacer, so Pacing or SleepInterval spaces requests across the whole
+// matrix rather than per locale. Per-locale failures are reported in
+// LocaleResults.Err; the returned error is only set for invalid options,
+// languages or regions, or when ctx ends. Locales are keyed by the
+// canonical codes ParseLanguage and ParseRegion return.
+func MatrixSearch(ctx context.Context, query string, langs []Language, regions []Region, opts SearchOptions) (map[Locale]LocaleResults, error) {
+	opts, err := prepareOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(langs) == 0 {
+		langs = []Language{opts.Lang}
+	}
+	if len(regions) == 0 {
+		regions = []Region{opts.Region}
+	}
+	langs = append([]Language(nil), langs...)
+	for i, lang := range langs {
+		if lang != "" {
+			if langs[i], err = ParseLanguage(string(lang)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	regions = append([]Region(nil), regions...)
+	for i, region := range regions {
+		if region != "" {
+			if regions[i], err = ParseRegion(string(region)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	pace := newPacer(opts)
+	out := make(map[Locale]LocaleResults, len(langs)*len(regions))
+	seen := make(map[Locale]bool)
+	var mu sync.Mutex
+	sem := make(chan struct{}, matrixConcurrency)
+	var wg sync.WaitGroup
+	for _, lang := range langs {
+		for _, region := range regions {
+			locale := Locale{Lang: lang, Region: region}
+			if seen[locale] {
+				continue
+			}
+			seen[locale] = true
+			wg.Add(1)
+			go func(locale Locale) {
+				defer wg.Done()
+				var res LocaleResults
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+					res = searchLocale(ctx, query, locale, opts, pace)
+				case <-ctx.Done():
+					res.Err = ctx.Err()
+				}
+				mu.Lock()
+				out[locale] = res
+				mu.Unlock()
+			}(locale)
+		}
+	}
+	wg.Wait()
+	return out, ctx.Err()
+}
+
+func searchLocale(ctx context.Context, query string, locale Locale, opts SearchOptions, pace *pacer) LocaleResults {
+	opts.Lang = locale.Lang
+	opts.Region = locale.Region
+	results, metadata, err := cachedSearch(ctx, query, opts, func() ([]SearchResult, *SERPMetadata, error) {
+		var results []SearchResult
+		metadata, err := walkSession(ctx, newSessionWithPacer(opts, pace), query, func(resp SearchResponse) error {
+			results = append(results, resp.Result)
+			return nil
+		})
+		return results, metadata, err
+	})
+	return LocaleResults{Results: reRank(results, opts), Metadata: metadata, Err: err}
+}
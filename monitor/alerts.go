@@ -0,0 +1,199 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/1hehaq/googlesearch"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Condition is what a Rule watches for.
+type Condition string
+
+const (
+	// DropsOut fires when Domain was within the top Top results of the
+	// previous run but is not anymore.
+	DropsOut Condition = "drops_out"
+	// Enters fires when Domain reaches the top Top results.
+	Enters Condition = "enters"
+	// NewDomain fires for every domain in the top Top results that was not
+	// there in the previous run.
+	NewDomain Condition = "new_domain"
+)
+
+// Rule is evaluated after each successful run of the Definition it is
+// attached to, against the previous successful run. Domains are compared
+// by registrable domain, so "example.com" also matches www.example.com.
+type Rule struct {
+	Condition Condition `json:"condition"`
+	// Domain is required for DropsOut and Enters and ignored otherwise.
+	Domain string `json:"domain,omitempty"`
+	// Top is the number of leading results considered; it defaults to 10.
+	Top int `json:"top,omitempty"`
+}
+
+func (r *Rule) normalize() error {
+	r.Domain = strings.ToLower(strings.TrimSpace(r.Domain))
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(r.Domain); err == nil {
+		r.Domain = domain
+	}
+	if r.Top <= 0 {
+		r.Top = 10
+	}
+	switch r.Condition {
+	case DropsOut, Enters:
+		if r.Domain == "" {
+			return fmt.Errorf("rule %s without domain", r.Condition)
+		}
+	case NewDomain:
+	default:
+		return fmt.Errorf("unknown rule condition %q", r.Condition)
+	}
+	return nil
+}
+
+// Alert is a Rule that fired.
+type Alert struct {
+	Definition string    `json:"definition"`
+	Query      string    `json:"query"`
+	Time       time.Time `json:"time"`
+	Rule       Rule      `json:"rule"`
+	Domain     string    `json:"domain"`
+	// Position and PreviousPosition are 1-based; 0 means the domain was
+	// not among the results of that run.
+	Position         int    `json:"position"`
+	PreviousPosition int    `json:"previous_position"`
+	Message          string `json:"message"`
+}
+
+// Evaluate returns the alerts rules raise for run cur given the previous
+// run prev.
+func Evaluate(rules []Rule, prev, cur Run) []Alert {
+	before := positions(prev.Results)
+	after := positions(cur.Results)
+
+	var alerts []Alert
+	for _, rule := range rules {
+		if rule.normalize() != nil {
+			continue
+		}
+		newAlert := func(domain string) Alert {
+			a := Alert{
+				Definition:       cur.Name,
+				Query:            cur.Query,
+				Time:             cur.Time,
+				Rule:             rule,
+				Domain:           domain,
+				Position:         after[domain],
+				PreviousPosition: before[domain],
+			}
+			a.Message = a.describe()
+			return a
+		}
+		inTop := func(pos int) bool { return pos > 0 && pos <= rule.Top }
+
+		switch rule.Condition {
+		case DropsOut:
+			if inTop(before[rule.Domain]) && !inTop(after[rule.Domain]) {
+				alerts = append(alerts, newAlert(rule.Domain))
+			}
+		case Enters:
+			if !inTop(before[rule.Domain]) && inTop(after[rule.Domain]) {
+				alerts = append(alerts, newAlert(rule.Domain))
+			}
+		case NewDomain:
+			var entered []string
+			for domain, pos := range after {
+				if inTop(pos) && !inTop(before[domain]) {
+					entered = append(entered, domain)
+				}
+			}
+			sort.Slice(entered, func(i, j int) bool { return after[entered[i]] < after[entered[j]] })
+			for _, domain := range entered {
+				alerts = append(alerts, newAlert(domain))
+			}
+		}
+	}
+	return alerts
+}
+
+func (a Alert) describe() string {
+	position := "not ranked"
+	if a.Position > 0 {
+		position = fmt.Sprintf("#%d", a.Position)
+	}
+	previous := "not ranked"
+	if a.PreviousPosition > 0 {
+		previous = fmt.Sprintf("#%d", a.PreviousPosition)
+	}
+	var what string
+	switch a.Rule.Condition {
+	case DropsOut:
+		what = fmt.Sprintf("%s dropped out of the top %d", a.Domain, a.Rule.Top)
+	case Enters:
+		what = fmt.Sprintf("%s entered the top %d", a.Domain, a.Rule.Top)
+	default:
+		what = fmt.Sprintf("new domain %s in the top %d", a.Domain, a.Rule.Top)
+	}
+	return fmt.Sprintf("%q: %s (%s, was %s)", a.Query, what, position, previous)
+}
+
+// positions maps registrable domains to their best 1-based position.
+func positions(results []googlesearch.SearchResult) map[string]int {
+	pos := make(map[string]int)
+	for _, summary := range googlesearch.AggregateByDomain(results) {
+		pos[summary.Domain] = summary.BestPosition
+	}
+	return pos
+}
+
+// previousRun returns the latest successful run of name before t.
+func (m *Monitor) previousRun(ctx context.Context, name string, t time.Time) (Run, bool, error) {
+	keys, err := m.store.List(ctx, runsPrefix+url.PathEscape(name)+"/")
+	if err != nil {
+		return Run{}, false, err
+	}
+	current := runKey(name, t)
+	for i := len(keys) - 1; i >= 0; i-- {
+		if keys[i] >= current {
+			continue
+		}
+		data, ok, err := m.store.Get(ctx, keys[i])
+		if err != nil {
+			return Run{}, false, err
+		}
+		var run Run
+		if ok && json.Unmarshal(data, &run) == nil && run.Error == "" {
+			return run, true, nil
+		}
+	}
+	return Run{}, false, nil
+}
+
+// alert evaluates the rules of def for run and hands the alerts to every
+// notifier. Nothing fires for the first successful run of a definition.
+func (m *Monitor) alert(ctx context.Context, def Definition, run Run) error {
+	if len(def.Rules) == 0 || run.Error != "" {
+		return nil
+	}
+	prev, ok, err := m.previousRun(ctx, def.Name, run.Time)
+	if err != nil || !ok {
+		return err
+	}
+	var errs []error
+	for _, a := range Evaluate(def.Rules, prev, run) {
+		for _, n := range m.Notifiers {
+			if err := n.Notify(ctx, a); err != nil {
+				errs = append(errs, fmt.Errorf("monitor: %s: notify: %w", def.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
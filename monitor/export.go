@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// ExportOptions filter what Export returns. Zero values select everything.
+type ExportOptions struct {
+	// Names selects definitions by name.
+	Names []string
+	// Domains selects registrable domains.
+	Domains []string
+	From    time.Time
+	To      time.Time
+}
+
+// Series is the position history of one domain for one definition.
+type Series struct {
+	Name   string  `json:"name"`
+	Query  string  `json:"query"`
+	Domain string  `json:"domain"`
+	Points []Point `json:"points"`
+}
+
+// Point is the best 1-based position of a domain in one run; 0 means it
+// was not among the results.
+type Point struct {
+	Time     time.Time `json:"time"`
+	Position int       `json:"position"`
+}
+
+// Export builds position time series from the stored runs. Every series
+// has a point for each successful run of its definition in the time range,
+// so gaps show up as position 0 rather than missing points. Series are
+// ordered by name and domain.
+func (m *Monitor) Export(ctx context.Context, opts ExportOptions) ([]Series, error) {
+	names := opts.Names
+	if len(names) == 0 {
+		defs, err := m.Definitions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, def := range defs {
+			names = append(names, def.Name)
+		}
+	}
+	domains := make(map[string]bool, len(opts.Domains))
+	for _, d := range opts.Domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if etld1, err := publicsuffix.EffectiveTLDPlusOne(d); err == nil {
+			d = etld1
+		}
+		domains[d] = true
+	}
+
+	var all []Series
+	for _, name := range names {
+		runs, err := m.Runs(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		var kept []Run
+		var ranked []map[string]int
+		seen := make(map[string]bool)
+		for _, run := range runs {
+			if run.Error != "" || (!opts.From.IsZero() && run.Time.Before(opts.From)) || (!opts.To.IsZero() && run.Time.After(opts.To)) {
+				continue
+			}
+			pos := positions(run.Results)
+			for domain := range pos {
+				if len(domains) == 0 || domains[domain] {
+					seen[domain] = true
+				}
+			}
+			kept = append(kept, run)
+			ranked = append(ranked, pos)
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		for domain := range seen {
+			s := Series{Name: name, Query: kept[len(kept)-1].Query, Domain: domain}
+			for i, run := range kept {
+				s.Points = append(s.Points, Point{Time: run.Time, Position: ranked[i][domain]})
+			}
+			all = append(all, s)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Name != all[j].Name {
+			return all[i].Name < all[j].Name
+		}
+		return all[i].Domain < all[j].Domain
+	})
+	return all, nil
+}
+
+// WriteCSV writes series in long format with the header
+// time,name,query,domain,position. Times are RFC 3339 in UTC and unranked
+// points have an empty position.
+func WriteCSV(w io.Writer, series []Series) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"time", "name", "query", "domain", "position"})
+	for _, s := range series {
+		for _, p := range s.Points {
+			position := ""
+			if p.Position > 0 {
+				position = strconv.Itoa(p.Position)
+			}
+			cw.Write([]string{p.Time.UTC().Format(time.RFC3339), s.Name, s.Query, s.Domain, position})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes series as a JSON array.
+func WriteJSON(w io.Writer, series []Series) error {
+	if series == nil {
+		series = []Series{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(series)
+}
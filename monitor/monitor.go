@@ -0,0 +1,405 @@
+// Package monitor runs Google searches on a schedule and records every run
+// in a googlesearch.Store, so rankings can be tracked over time.
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1hehaq/googlesearch"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	definitionsPrefix = "monitor/queries/"
+	runsPrefix        = "monitor/runs/"
+	// runTimeFormat has nanoseconds so runs in the same second keep
+	// their own keys.
+	runTimeFormat = "20060102T150405.000000000Z"
+)
+
+// mobileUserAgent is sent for definitions with Device "mobile".
+const mobileUserAgent = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36"
+
+// Definition is a monitored query.
+type Definition struct {
+	// Name identifies the definition; it defaults to Query.
+	Name  string `json:"name"`
+	Query string `json:"query"`
+	// Schedule is a standard five field cron expression such as
+	// "0 6 * * *", or a descriptor like "@hourly" or "@every 6h".
+	Schedule string `json:"schedule"`
+	// Engine selects the search engine; only "google" (the default) is
+	// supported.
+	Engine     string                `json:"engine,omitempty"`
+	Location   string                `json:"location,omitempty"`
+	Device     string                `json:"device,omitempty"`
+	Lang       googlesearch.Language `json:"lang,omitempty"`
+	Region     googlesearch.Region   `json:"region,omitempty"`
+	NumResults int                   `json:"num_results,omitempty"`
+	// Labels are attached to every search of the definition and stored
+	// with its runs.
+	Labels googlesearch.Labels `json:"labels,omitempty"`
+	// Rules are checked after every successful run.
+	Rules []Rule `json:"rules,omitempty"`
+}
+
+// Run is the stored outcome of running a Definition once.
+type Run struct {
+	Name    string                      `json:"name"`
+	Query   string                      `json:"query"`
+	Time    time.Time                   `json:"time"`
+	Results []googlesearch.SearchResult `json:"results,omitempty"`
+	Error   string                      `json:"error,omitempty"`
+	Labels  googlesearch.Labels         `json:"labels,omitempty"`
+}
+
+// Monitor schedules Definitions and stores their runs. All runs go through
+// one googlesearch.Searcher, so they share its pacing limits, and each
+// definition is a profile of it with its own cookies.
+type Monitor struct {
+	store    googlesearch.Store
+	base     googlesearch.SearchOptions
+	searcher *googlesearch.Searcher
+
+	// Notifiers receive the alerts raised by definition rules.
+	Notifiers []Notifier
+
+	// OnRun is called after every run, successful or not.
+	OnRun func(Run)
+
+	// OnError is called when a run started by Start fails, including
+	// failures to store the run or to send its alerts.
+	OnError func(Definition, error)
+
+	mu      sync.Mutex
+	closed  bool
+	closing chan struct{}
+	// running counts Start loops and runs in progress; abort cancels them
+	// once Close gives up waiting.
+	running sync.WaitGroup
+	aborted context.Context
+	abort   context.CancelFunc
+}
+
+// New returns a Monitor persisting definitions and runs in store. base
+// holds the search options shared by all definitions, such as proxies and
+// pacing.
+func New(store googlesearch.Store, base googlesearch.SearchOptions) (*Monitor, error) {
+	searcher, err := googlesearch.NewSearcher(base)
+	if err != nil {
+		return nil, err
+	}
+	m := &Monitor{store: store, base: base, searcher: searcher, closing: make(chan struct{})}
+	m.aborted, m.abort = context.WithCancel(context.Background())
+	return m, nil
+}
+
+// begin registers a run or Start loop so Close waits for it.
+func (m *Monitor) begin(ctx context.Context) (context.Context, func(), error) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return ctx, nil, googlesearch.ErrClosed
+	}
+	m.running.Add(1)
+	m.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(m.aborted, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+		m.running.Done()
+	}, nil
+}
+
+// Close stops the scheduler: Start returns without starting new runs and
+// the runs in progress are allowed to finish, so their results are stored
+// and their alerts sent. If ctx ends first, those runs are cancelled and
+// waited for, and Close returns ctx's error. The Searcher is closed last,
+// which saves its sessions if base has a SessionFile.
+func (m *Monitor) Close(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.closing)
+	}
+	m.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		m.running.Wait()
+		close(drained)
+	}()
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+		m.abort()
+		<-drained
+	}
+	m.abort()
+	return errors.Join(err, m.searcher.Close(context.WithoutCancel(ctx)))
+}
+
+func (d *Definition) normalize() error {
+	if strings.TrimSpace(d.Query) == "" {
+		return fmt.Errorf("monitor: definition without query")
+	}
+	if d.Name == "" {
+		d.Name = d.Query
+	}
+	if d.Engine == "" {
+		d.Engine = "google"
+	}
+	if d.Engine != "google" {
+		return fmt.Errorf("monitor: %s: unsupported engine %q", d.Name, d.Engine)
+	}
+	switch d.Device {
+	case "", "desktop", "mobile":
+	default:
+		return fmt.Errorf("monitor: %s: unknown device %q", d.Name, d.Device)
+	}
+	if d.Lang != "" {
+		lang, err := googlesearch.ParseLanguage(string(d.Lang))
+		if err != nil {
+			return fmt.Errorf("monitor: %s: %w", d.Name, err)
+		}
+		d.Lang = lang
+	}
+	if d.Region != "" {
+		region, err := googlesearch.ParseRegion(string(d.Region))
+		if err != nil {
+			return fmt.Errorf("monitor: %s: %w", d.Name, err)
+		}
+		d.Region = region
+	}
+	if _, err := cron.ParseStandard(d.Schedule); err != nil {
+		return fmt.Errorf("monitor: %s: schedule: %w", d.Name, err)
+	}
+	for i := range d.Rules {
+		if err := d.Rules[i].normalize(); err != nil {
+			return fmt.Errorf("monitor: %s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
+// Add validates def and stores it, replacing a definition of the same name.
+func (m *Monitor) Add(ctx context.Context, def Definition) error {
+	if err := def.normalize(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(def)
+	if err != nil {
+		return err
+	}
+	return m.store.Put(ctx, definitionsPrefix+url.PathEscape(def.Name), data)
+}
+
+// Remove deletes the definition called name. Its runs are kept.
+func (m *Monitor) Remove(ctx context.Context, name string) error {
+	return m.store.Delete(ctx, definitionsPrefix+url.PathEscape(name))
+}
+
+// Definitions returns the stored definitions ordered by name.
+func (m *Monitor) Definitions(ctx context.Context) ([]Definition, error) {
+	keys, err := m.store.List(ctx, definitionsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var defs []Definition
+	for _, key := range keys {
+		data, ok, err := m.store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		var def Definition
+		if !ok || json.Unmarshal(data, &def) != nil {
+			continue
+		}
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs, nil
+}
+
+// searcherFor returns the profile of the Monitor's Searcher that runs def.
+func (m *Monitor) searcherFor(def Definition) (*googlesearch.Searcher, error) {
+	overrides := googlesearch.QueryOverrides{
+		NumResults: def.NumResults,
+		Lang:       def.Lang,
+		Region:     def.Region,
+		Location:   def.Location,
+	}
+	if overrides.NumResults == 0 && m.base.NumResults == 0 {
+		overrides.NumResults = 10
+	}
+	if def.Device == "mobile" {
+		overrides.UserAgent = mobileUserAgent
+	}
+	return m.searcher.Profile(def.Name).With(overrides)
+}
+
+// labels returns the labels of a run of def: those of the base options,
+// overridden by those of ctx and then by the definition's.
+func (m *Monitor) labels(ctx context.Context, def Definition) googlesearch.Labels {
+	var labels googlesearch.Labels
+	for _, layer := range []googlesearch.Labels{m.base.Labels, googlesearch.LabelsFromContext(ctx), def.Labels} {
+		for k, v := range layer {
+			if labels == nil {
+				labels = make(googlesearch.Labels)
+			}
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+// RunOnce runs def now, stores the run and notifies about any alerts its
+// rules raise.
+func (m *Monitor) RunOnce(ctx context.Context, def Definition) (Run, error) {
+	if err := def.normalize(); err != nil {
+		return Run{}, err
+	}
+	ctx, done, berr := m.begin(ctx)
+	if berr != nil {
+		return Run{}, berr
+	}
+	defer done()
+	labels := m.labels(ctx, def)
+	ctx = googlesearch.WithLabels(ctx, labels)
+	run := Run{Name: def.Name, Query: def.Query, Time: time.Now().UTC(), Labels: labels}
+	searcher, err := m.searcherFor(def)
+	if err == nil {
+		run.Results, err = searcher.Search(ctx, def.Query)
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	data, merr := json.Marshal(run)
+	if merr != nil {
+		return run, merr
+	}
+	if perr := m.store.Put(ctx, runKey(def.Name, run.Time), data); perr != nil {
+		return run, perr
+	}
+	if m.OnRun != nil {
+		m.OnRun(run)
+	}
+	if err == nil {
+		err = m.alert(ctx, def, run)
+	}
+	return run, err
+}
+
+// Runs returns the stored runs of the definition called name, oldest first.
+func (m *Monitor) Runs(ctx context.Context, name string) ([]Run, error) {
+	keys, err := m.store.List(ctx, runsPrefix+url.PathEscape(name)+"/")
+	if err != nil {
+		return nil, err
+	}
+	var runs []Run
+	for _, key := range keys {
+		data, ok, err := m.store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		var run Run
+		if ok && json.Unmarshal(data, &run) == nil {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+func runKey(name string, t time.Time) string {
+	return runsPrefix + url.PathEscape(name) + "/" + t.UTC().Format(runTimeFormat) + ".json"
+}
+
+// Start runs the stored definitions on their schedules until ctx is
+// done or the Monitor is closed, in which case it returns nil. Definitions
+// added or removed while it runs are picked up at the next tick. Runs
+// happen one at a time; failed ones are reported to OnError.
+func (m *Monitor) Start(ctx context.Context) error {
+	ctx, done, err := m.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	next := make(map[string]time.Time)
+	for {
+		defs, err := m.Definitions(ctx)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		wake := now.Add(time.Minute)
+		active := make(map[string]bool, len(defs))
+		for _, def := range defs {
+			active[def.Name] = true
+			schedule, err := cron.ParseStandard(def.Schedule)
+			if err != nil {
+				continue
+			}
+			due, ok := next[def.Name]
+			if !ok {
+				due = schedule.Next(now)
+				next[def.Name] = due
+			}
+			if !due.After(now) {
+				if m.isClosing() {
+					return nil
+				}
+				if _, err := m.RunOnce(ctx, def); err != nil && m.OnError != nil {
+					m.OnError(def, err)
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				due = schedule.Next(time.Now())
+				next[def.Name] = due
+			}
+			if due.Before(wake) {
+				wake = due
+			}
+		}
+		for name := range next {
+			if !active[name] {
+				delete(next, name)
+			}
+		}
+
+		timer := time.NewTimer(time.Until(wake))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-m.closing:
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+func (m *Monitor) isClosing() bool {
+	select {
+	case <-m.closing:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,18 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunKeyDistinguishesRunsInOneSecond(t *testing.T) {
+	at := time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC)
+	first := runKey("golang", at)
+	second := runKey("golang", at.Add(time.Millisecond))
+	if first == second {
+		t.Fatalf("runs 1ms apart share the key %s", first)
+	}
+	if first >= second {
+		t.Errorf("run keys do not sort by time: %s >= %s", first, second)
+	}
+}
@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"strings"
+)
+
+// Notifier delivers alerts.
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// NotifierFunc adapts a function to the Notifier interface.
+type NotifierFunc func(ctx context.Context, alert Alert) error
+
+func (f NotifierFunc) Notify(ctx context.Context, alert Alert) error {
+	return f(ctx, alert)
+}
+
+// WebhookNotifier POSTs each alert as JSON to URL. When Secret is set the
+// request carries an X-Signature-256 header like googlesearch.WebhookSink.
+type WebhookNotifier struct {
+	URL    string
+	Secret []byte
+	Client *http.Client
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	header := http.Header{"Content-Type": {"application/json"}}
+	if len(w.Secret) > 0 {
+		mac := hmac.New(sha256.New, w.Secret)
+		mac.Write(body)
+		header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	return post(ctx, w.Client, w.URL, header, body)
+}
+
+// SlackNotifier posts alerts to a Slack incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+func (s *SlackNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(map[string]string{"text": alert.Message})
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.Client, s.WebhookURL, http.Header{"Content-Type": {"application/json"}}, body)
+}
+
+func post(ctx context.Context, client *http.Client, url string, header http.Header, body []byte) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return nil
+}
+
+// SMTPNotifier mails alerts through the server at Addr ("host:port").
+// Auth may be nil for servers that accept unauthenticated mail.
+type SMTPNotifier struct {
+	Addr string
+	Auth smtp.Auth
+	From string
+	To   []string
+}
+
+func (s *SMTPNotifier) Notify(ctx context.Context, alert Alert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(s.Addr, s.Auth, s.From, s.To, s.message(alert))
+}
+
+// message formats the mail for alert. The subject is Q-encoded when
+// needed, so a definition name cannot add header lines.
+func (s *SMTPNotifier) message(alert Alert) []byte {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[googlesearch] "+alert.Definition))
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n", alert.Message)
+	return []byte(msg.String())
+}
@@ -0,0 +1,29 @@
+package monitor
+
+import (
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestSMTPSubjectCannotInjectHeaders(t *testing.T) {
+	s := &SMTPNotifier{From: "monitor@example.com", To: []string{"ops@example.com"}}
+	msg, err := mail.ReadMessage(strings.NewReader(string(s.message(Alert{
+		Definition: "golang\r\nBcc: victim@example.com",
+		Message:    "example.com left the top 3",
+	}))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bcc := msg.Header.Get("Bcc"); bcc != "" {
+		t.Fatalf("definition name injected Bcc: %s", bcc)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[googlesearch] golang\r\nBcc: victim@example.com"; subject != want {
+		t.Errorf("subject %q, want %q", subject, want)
+	}
+}
@@ -0,0 +1,78 @@
+package googlesearch
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// TextNormalization cleans up the titles and descriptions of results as
+// they are extracted. The zero value leaves the text as Google sent it.
+type TextNormalization struct {
+	// Whitespace collapses runs of whitespace, including non-breaking
+	// spaces, into single spaces and trims the ends.
+	Whitespace bool
+	// StripEllipsis removes the "…" or "..." Google puts at the start or
+	// end of cut off snippets and titles.
+	StripEllipsis bool
+	// DecodeEntities decodes HTML entities left in the text, as found in
+	// double escaped titles like "Q&amp;A".
+	DecodeEntities bool
+	// MaxDescriptionRunes truncates descriptions to at most this many
+	// runes, at a word boundary where possible; zero keeps them whole.
+	MaxDescriptionRunes int
+}
+
+func (n TextNormalization) enabled() bool {
+	return n != TextNormalization{}
+}
+
+func (n TextNormalization) apply(results []SearchResult) {
+	if !n.enabled() {
+		return
+	}
+	for i := range results {
+		results[i].Title = n.text(results[i].Title)
+		results[i].Description = n.text(results[i].Description)
+		if n.MaxDescriptionRunes > 0 {
+			results[i].Description = truncateRunes(results[i].Description, n.MaxDescriptionRunes)
+		}
+	}
+}
+
+func (n TextNormalization) text(s string) string {
+	if n.DecodeEntities {
+		s = html.UnescapeString(s)
+	}
+	if n.Whitespace {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+	if n.StripEllipsis {
+		s = strings.TrimSpace(s)
+		for _, dots := range []string{"…", "..."} {
+			s = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(s, dots), dots))
+		}
+	}
+	return s
+}
+
+// truncateRunes shortens s to at most max runes, cutting at the last space
+// when that keeps at least half of the text.
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	cut := 0
+	for i := range s {
+		if max == 0 {
+			cut = i
+			break
+		}
+		max--
+	}
+	s = s[:cut]
+	if i := strings.LastIndex(s, " "); i > len(s)/2 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
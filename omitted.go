@@ -0,0 +1,38 @@
+package googlesearch
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// OmittedResults describes Google's "we have omitted some entries very
+// similar to those already displayed" notice. Exhaustive crawls can repeat
+// the search through RepeatURL, or with ExtraParams filter=0, to get the
+// folded results back.
+type OmittedResults struct {
+	Notice    string
+	RepeatURL string
+}
+
+func extractOmittedResults(doc *goquery.Document) *OmittedResults {
+	var omitted *OmittedResults
+	doc.Find("#ofr a[href], p#ofr a[href], a[href*='filter=0']").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		href, _ := s.Attr("href")
+		u, err := url.Parse(href)
+		if err != nil || u.Query().Get("filter") != "0" {
+			return true
+		}
+		if !strings.HasPrefix(u.Path, "/search") {
+			return true
+		}
+		notice := normalizeSpace(s.Parent().Text())
+		if ofr := s.Closest("#ofr"); ofr.Length() > 0 {
+			notice = normalizeSpace(ofr.Text())
+		}
+		omitted = &OmittedResults{Notice: notice, RepeatURL: resolveGoogleURL(doc.Url, href)}
+		return false
+	})
+	return omitted
+}
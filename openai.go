@@ -0,0 +1,84 @@
+package googlesearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FunctionDefinition describes the search function in the shape expected
+// by OpenAI-style function calling ("tools": [{"type": "function",
+// "function": ...}]).
+type FunctionDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+// FunctionArguments are the arguments of a google_search function call.
+type FunctionArguments struct {
+	Query   string `json:"query"`
+	Count   int    `json:"count,omitempty"`
+	Recency string `json:"recency,omitempty"`
+	Site    string `json:"site,omitempty"`
+}
+
+const functionParameters = `{
+  "type": "object",
+  "properties": {
+    "query": {"type": "string", "description": "The search query."},
+    "count": {"type": "integer", "minimum": 1, "maximum": 50, "description": "Number of results to return, 10 by default."},
+    "recency": {"type": "string", "enum": ["any", "hour", "day", "week", "month", "year"], "description": "Only return pages indexed within this period."},
+    "site": {"type": "string", "description": "Restrict results to this domain, e.g. \"go.dev\"."}
+  },
+  "required": ["query"]
+}`
+
+// SearchFunction returns the definition of the google_search function to
+// advertise to a chat model.
+func SearchFunction() FunctionDefinition {
+	return FunctionDefinition{
+		Name:        "google_search",
+		Description: "Search Google for current information and return the top results with title, URL and snippet.",
+		Parameters:  json.RawMessage(functionParameters),
+	}
+}
+
+// CallSearchFunction executes a google_search function call. arguments is
+// the raw JSON argument string produced by the model; the result is
+// formatted with FormatText and can be sent back as the tool message.
+func CallSearchFunction(ctx context.Context, arguments string, opts SearchOptions) (string, error) {
+	var args FunctionArguments
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("google: invalid function arguments: %w", err)
+	}
+	query := strings.TrimSpace(args.Query)
+	if query == "" {
+		return "", fmt.Errorf("google: function call without query")
+	}
+	tr, err := ParseTimeRange(args.Recency)
+	if err != nil {
+		return "", err
+	}
+
+	opts.TimeRange = tr
+	n := args.Count
+	if n <= 0 {
+		n = 10
+	}
+	if n > 50 {
+		n = 50
+	}
+	var results []SearchResult
+	if args.Site != "" {
+		results, err = SiteSearch(ctx, args.Site, query, n, opts)
+	} else {
+		opts.NumResults = n
+		results, _, err = SearchWithMetadata(ctx, query, opts)
+	}
+	if err != nil {
+		return "", err
+	}
+	return FormatText(results), nil
+}
@@ -0,0 +1,252 @@
+package googlesearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// PacingProfile controls how fast a search paginates. Delays between page
+// requests are drawn from a triangular distribution between MinDelay and
+// MaxDelay. After PagesPerSession pages the search pauses for
+// SessionCooldown and continues with a fresh session (new user agent and
+// cookies); zero disables session rotation.
+type PacingProfile struct {
+	Name            string
+	MinDelay        time.Duration
+	MaxDelay        time.Duration
+	PagesPerSession int
+	SessionCooldown time.Duration
+}
+
+var (
+	PacingAggressive = PacingProfile{
+		Name:     "aggressive",
+		MinDelay: 500 * time.Millisecond,
+		MaxDelay: 2 * time.Second,
+	}
+	PacingNormal = PacingProfile{
+		Name:            "normal",
+		MinDelay:        3 * time.Second,
+		MaxDelay:        8 * time.Second,
+		PagesPerSession: 10,
+		SessionCooldown: 30 * time.Second,
+	}
+	PacingStealth = PacingProfile{
+		Name:            "stealth",
+		MinDelay:        10 * time.Second,
+		MaxDelay:        30 * time.Second,
+		PagesPerSession: 4,
+		SessionCooldown: 3 * time.Minute,
+	}
+)
+
+// PacingProfileByName returns one of the predefined pacing profiles.
+func PacingProfileByName(name string) (PacingProfile, error) {
+	for _, profile := range []PacingProfile{PacingAggressive, PacingNormal, PacingStealth} {
+		if profile.Name == name {
+			return profile, nil
+		}
+	}
+	return PacingProfile{}, fmt.Errorf("google: unknown pacing profile: %q", name)
+}
+
+func (p PacingProfile) delay() time.Duration {
+	if p.MaxDelay <= p.MinDelay {
+		return p.MinDelay
+	}
+	spread := float64(p.MaxDelay - p.MinDelay)
+	return p.MinDelay + time.Duration(spread*(rand.Float64()+rand.Float64())/2)
+}
+
+// AdaptiveThrottle tunes the delay between page requests to the block
+// signals Google sends. Every captcha or 429 multiplies the delay by
+// Backoff, starting from MinDelay and capped at MaxDelay; every
+// RecoverAfter pages in a row that were not blocked divide it by Backoff
+// again, until it drops below MinDelay and only the regular pacing is
+// left. The throttled delay replaces the Pacing or SleepInterval delay
+// whenever it is longer. Since a block ends the search it happened in,
+// the throttle pays off with a Searcher, whose pacing spans searches.
+type AdaptiveThrottle struct {
+	// MinDelay defaults to 2s, MaxDelay to 5m, Backoff to 2 and
+	// RecoverAfter to 5.
+	MinDelay     time.Duration
+	MaxDelay     time.Duration
+	Backoff      float64
+	RecoverAfter int
+}
+
+func (t AdaptiveThrottle) withDefaults() AdaptiveThrottle {
+	if t.MinDelay <= 0 {
+		t.MinDelay = 2 * time.Second
+	}
+	if t.MaxDelay <= 0 {
+		t.MaxDelay = 5 * time.Minute
+	}
+	if t.Backoff <= 1 {
+		t.Backoff = 2
+	}
+	if t.RecoverAfter <= 0 {
+		t.RecoverAfter = 5
+	}
+	return t
+}
+
+// pacer applies either the configured PacingProfile or the plain
+// SleepInterval between page requests. It lives on the session, so a
+// Searcher keeps pacing across queries.
+type pacer struct {
+	profile       *PacingProfile
+	sleepInterval time.Duration
+	throttle      *AdaptiveThrottle
+
+	mu             sync.Mutex
+	pagesInSession int
+	lastRequest    time.Time
+	// throttled is the current adaptive delay and successes the number
+	// of unblocked pages since it last changed.
+	throttled time.Duration
+	successes int
+}
+
+func newPacer(opts SearchOptions) *pacer {
+	p := &pacer{
+		profile:       opts.Pacing,
+		sleepInterval: time.Duration(opts.SleepInterval) * time.Second,
+	}
+	if opts.Throttle != nil {
+		t := opts.Throttle.withDefaults()
+		p.throttle = &t
+	}
+	return p
+}
+
+// wait blocks until the next page may be requested. It reports whether the
+// caller should start a fresh session because the profile's page budget
+// for the current one is used up.
+//
+// Concurrent callers each reserve their own slot, so goroutines sharing a
+// Searcher are spaced out instead of all firing after the same delay.
+func (p *pacer) wait(ctx context.Context) (bool, error) {
+	p.mu.Lock()
+	now := time.Now()
+	if p.lastRequest.IsZero() {
+		p.lastRequest = now
+		p.pagesInSession++
+		p.mu.Unlock()
+		return false, ctx.Err()
+	}
+
+	delay := p.sleepInterval
+	rotate := false
+	if p.profile != nil {
+		delay = p.profile.delay()
+		if p.profile.PagesPerSession > 0 && p.pagesInSession >= p.profile.PagesPerSession {
+			delay += p.profile.SessionCooldown
+			p.pagesInSession = 0
+			rotate = true
+		}
+	}
+	if p.throttled > delay {
+		delay = p.throttled
+	}
+	at := p.lastRequest.Add(delay)
+	if at.Before(now) {
+		at = now
+	}
+	p.lastRequest = at
+	p.pagesInSession++
+	p.mu.Unlock()
+
+	if err := sleepUntil(ctx, now, at); err != nil {
+		return false, err
+	}
+	return rotate, nil
+}
+
+// pause blocks for the usual delay between page requests without counting
+// a page against the session budget, for retrying a page within the
+// current session.
+func (p *pacer) pause(ctx context.Context) error {
+	p.mu.Lock()
+	now := time.Now()
+	delay := p.sleepInterval
+	if p.profile != nil {
+		delay = p.profile.delay()
+	}
+	if p.throttled > delay {
+		delay = p.throttled
+	}
+	at := p.lastRequest.Add(delay)
+	if at.Before(now) {
+		at = now
+	}
+	p.lastRequest = at
+	p.mu.Unlock()
+	return sleepUntil(ctx, now, at)
+}
+
+// sleepUntil blocks from now until at or until ctx ends.
+func sleepUntil(ctx context.Context, now, at time.Time) error {
+	delay := at.Sub(now)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// observe adjusts the adaptive delay to the outcome of a page request.
+// Errors other than blocks, such as network failures, leave it alone.
+func (p *pacer) observe(err error) {
+	if p.throttle == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t := p.throttle
+	switch {
+	case errors.Is(err, ErrBlocked):
+		p.successes = 0
+		if p.throttled < t.MinDelay {
+			p.throttled = t.MinDelay
+		} else {
+			p.throttled = time.Duration(float64(p.throttled) * t.Backoff)
+		}
+		if p.throttled > t.MaxDelay {
+			p.throttled = t.MaxDelay
+		}
+	case err == nil || errors.Is(err, ErrNoResults):
+		if p.throttled == 0 {
+			return
+		}
+		p.successes++
+		if p.successes < t.RecoverAfter {
+			return
+		}
+		p.successes = 0
+		p.throttled = time.Duration(float64(p.throttled) / t.Backoff)
+		if p.throttled < t.MinDelay {
+			p.throttled = 0
+		}
+	}
+}
+
+// done records that a page request has finished, so the next delay is
+// measured from the end of this one.
+func (p *pacer) done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if now := time.Now(); now.After(p.lastRequest) {
+		p.lastRequest = now
+	}
+}
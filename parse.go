@@ -0,0 +1,241 @@
+package googlesearch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	// maxPageBytes bounds how much of a response or file is parsed.
+	// Real result pages are well below 2 MB.
+	maxPageBytes = 8 << 20
+	// maxTreeDepth caps element nesting; deeper subtrees are dropped
+	// before extraction so recursive helpers cannot exhaust the stack.
+	maxTreeDepth = 256
+	// The HTML parser walks the open elements on most start tags, so its
+	// time grows with the number of tags times their nesting depth, and a
+	// few megabytes of unclosed tags would stall it. Pages opening more
+	// than maxOpenElements elements at once, or whose tags sum to more
+	// than maxNestingWork open elements, are rejected before parsing.
+	// Real result pages stay well below both.
+	maxOpenElements = 4 * maxTreeDepth
+	maxNestingWork  = 1 << 24
+)
+
+// ErrMalformedPage is returned when a page cannot be processed safely.
+var ErrMalformedPage = errors.New("google: malformed results page")
+
+// ParseHTML runs the full extraction pipeline on a saved results page and
+// returns its organic results and metadata. Captcha pages yield ErrBlocked.
+func ParseHTML(r io.Reader) ([]SearchResult, *SERPMetadata, error) {
+	doc, err := newDocument(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isCaptchaPage(doc) {
+		return nil, nil, ErrBlocked
+	}
+	page, err := parseDocument(doc, SearchOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return page.results, &page.metadata, nil
+}
+
+// ParseFile is ParseHTML for a file on disk.
+func ParseFile(path string) ([]SearchResult, *SERPMetadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	return ParseHTML(f)
+}
+
+// newDocument parses at most maxPageBytes of r and prunes pathologically
+// deep subtrees, as served by some interfering middleboxes.
+func newDocument(r io.Reader) (*goquery.Document, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxPageBytes))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNesting(data); err != nil {
+		return nil, err
+	}
+	root, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedPage, err)
+	}
+	pruneDepth(root, maxTreeDepth)
+	return goquery.NewDocumentFromNode(root), nil
+}
+
+// checkNesting tokenizes data and fails with ErrMalformedPage when it
+// nests beyond maxOpenElements or maxNestingWork. It is linear in the size
+// of data. Void elements and elements whose end tag is optional are not
+// counted, and an end tag closes every element opened after its match, as
+// the parser would.
+func checkNesting(data []byte) error {
+	type open struct {
+		tag  atom.Atom
+		name string
+	}
+	var stack []open
+	work := 0
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return nil
+		}
+		if tt != html.StartTagToken && tt != html.EndTagToken {
+			continue
+		}
+		name, _ := z.TagName()
+		el := open{tag: atom.Lookup(name)}
+		if el.tag == 0 {
+			el.name = string(name)
+		}
+		if unnestedElements[el.tag] {
+			continue
+		}
+		if tt == html.StartTagToken {
+			stack = append(stack, el)
+			work += len(stack)
+			if len(stack) > maxOpenElements {
+				return fmt.Errorf("%w: more than %d nested elements", ErrMalformedPage, maxOpenElements)
+			}
+			if work > maxNestingWork {
+				return fmt.Errorf("%w: too deeply nested", ErrMalformedPage)
+			}
+			continue
+		}
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i] == el {
+				stack = stack[:i]
+				break
+			}
+		}
+	}
+}
+
+// unnestedElements are the void elements and those implicitly closed by a
+// sibling, which checkNesting does not count.
+var unnestedElements = map[atom.Atom]bool{
+	atom.Area: true, atom.Base: true, atom.Br: true, atom.Col: true,
+	atom.Embed: true, atom.Hr: true, atom.Img: true, atom.Input: true,
+	atom.Link: true, atom.Meta: true, atom.Param: true, atom.Source: true,
+	atom.Track: true, atom.Wbr: true,
+	atom.P: true, atom.Li: true, atom.Dt: true, atom.Dd: true,
+	atom.Option: true, atom.Optgroup: true, atom.Tr: true, atom.Td: true,
+	atom.Th: true, atom.Thead: true, atom.Tbody: true, atom.Tfoot: true,
+	atom.Colgroup: true, atom.Rb: true, atom.Rt: true, atom.Rp: true,
+	atom.Rtc: true,
+}
+
+// pruneDepth detaches all nodes nested deeper than maxDepth. It walks the
+// tree iteratively so the check itself cannot overflow the stack.
+func pruneDepth(root *html.Node, maxDepth int) {
+	type item struct {
+		node  *html.Node
+		depth int
+	}
+	stack := []item{{root, 0}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if top.depth >= maxDepth {
+			for child := top.node.FirstChild; child != nil; {
+				next := child.NextSibling
+				top.node.RemoveChild(child)
+				child = next
+			}
+			continue
+		}
+		for child := top.node.FirstChild; child != nil; child = child.NextSibling {
+			stack = append(stack, item{child, top.depth + 1})
+		}
+	}
+}
+
+// parseDocument extracts results and metadata. A panic in one of the
+// extractors, e.g. on an unexpected layout, is turned into
+// ErrMalformedPage instead of crashing the caller.
+func parseDocument(doc *goquery.Document, opts SearchOptions) (page *serpPage, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			page, err = nil, fmt.Errorf("%w: %v", ErrMalformedPage, r)
+		}
+	}()
+	metadata := extractMetadata(doc, opts)
+	var results []SearchResult
+	if opts.Extractor != nil {
+		results, metadata.Features, err = opts.Extractor.Extract(doc.Nodes[0])
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		results = extractResults(doc, opts)
+	}
+	if !opts.KeepNonWebLinks {
+		results = filterWebResults(results)
+	}
+	opts.Normalize.apply(results)
+	for i := range results {
+		results[i].Category = ClassifyURL(results[i].URL)
+	}
+	return &serpPage{
+		results:          results,
+		metadata:         metadata,
+		moreResultsTerms: extractMoreResultsTerms(doc),
+	}, nil
+}
+
+// innerHTML returns the serialized children of the first node in s, or ""
+// when s is empty.
+func innerHTML(s *goquery.Selection) string {
+	if s.Length() == 0 {
+		return ""
+	}
+	h, err := s.Html()
+	if err != nil {
+		return ""
+	}
+	return cleanText(h)
+}
+
+// dataAttributes collects the data-* attributes of s and its descendants.
+func dataAttributes(s *goquery.Selection) map[string]string {
+	var data map[string]string
+	collect := func(i int, n *goquery.Selection) {
+		for _, attr := range n.Nodes[0].Attr {
+			name := strings.TrimPrefix(attr.Key, "data-")
+			if name == attr.Key || name == "" {
+				continue
+			}
+			if data == nil {
+				data = make(map[string]string)
+			}
+			if _, ok := data[name]; !ok {
+				data[name] = cleanText(attr.Val)
+			}
+		}
+	}
+	s.Each(collect)
+	s.Find("*").Each(collect)
+	return data
+}
+
+// cleanText replaces invalid UTF-8 so extracted strings are always safe to
+// encode as JSON or print.
+func cleanText(s string) string {
+	return strings.ToValidUTF8(s, "\uFFFD")
+}
@@ -0,0 +1,529 @@
+package googlesearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// parseDeadline is how long one fuzz input may take before the parser is
+// considered to have run away.
+const parseDeadline = 10 * time.Second
+
+// addSeedPages adds every page of testdata/serp and testdata/features to
+// the fuzz corpus, plus truncated and deeply nested variants.
+func addSeedPages(f *testing.F) {
+	serp, err := filepath.Glob(filepath.Join("testdata", "serp", "*.html"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	features, err := filepath.Glob(filepath.Join("testdata", "features", "*.html"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	pages := append(serp, features...)
+	for _, page := range pages {
+		data, err := os.ReadFile(page)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+		f.Add(data[:len(data)/2])
+	}
+	f.Add([]byte(strings.Repeat("<div>", 10000) + `<div class="ezO2md"><a href="/url?q=https://example.com/">x</a></div>`))
+	f.Add([]byte("<div class=\"ezO2md\"><a href=\"/url?q=https://example.com/\"><span class=\"CVA68e\">\xff\xfe</span></a></div>"))
+}
+
+// withDeadline runs fn and fails t if it does not return within
+// parseDeadline.
+func withDeadline(t *testing.T, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(parseDeadline):
+		t.Fatalf("parsing did not finish within %v", parseDeadline)
+	}
+}
+
+// treeDepth returns the deepest nesting level below n.
+func treeDepth(n *html.Node) int {
+	deepest := 0
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		deepest = max(deepest, treeDepth(child)+1)
+	}
+	return deepest
+}
+
+func FuzzParseDocument(f *testing.F) {
+	addSeedPages(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		withDeadline(t, func() {
+			doc, err := newDocument(bytes.NewReader(data))
+			if err != nil {
+				return
+			}
+			if depth := treeDepth(doc.Nodes[0]); depth > maxTreeDepth {
+				t.Errorf("tree depth %d after pruning, want at most %d", depth, maxTreeDepth)
+			}
+			page, err := parseDocument(doc, SearchOptions{KeepHTML: true, KeepBlockHTML: true, KeepNonWebLinks: true})
+			if err != nil {
+				// Without a custom Extractor, parseDocument only fails
+				// when it recovered from a panic.
+				t.Fatalf("parseDocument: %v", err)
+			}
+			for _, result := range page.results {
+				checkValidUTF8(t, result)
+			}
+		})
+	})
+}
+
+func FuzzExtractResult(f *testing.F) {
+	addSeedPages(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		withDeadline(t, func() {
+			doc, err := newDocument(bytes.NewReader(data))
+			if err != nil {
+				return
+			}
+			selectors := CurrentSelectors()
+			opts := SearchOptions{KeepHTML: true, KeepBlockHTML: true}
+			for _, node := range doc.Find(selectors.Result).Nodes {
+				result, ok := extractResult(doc.FindNodes(node), doc.Url, selectors, opts)
+				if !ok {
+					continue
+				}
+				if result.URL == "" {
+					t.Errorf("extracted result without URL: %+v", result)
+				}
+				checkValidUTF8(t, result)
+			}
+		})
+	})
+}
+
+func checkValidUTF8(t *testing.T, result SearchResult) {
+	t.Helper()
+	for _, s := range []string{result.Title, result.Description, result.TitleHTML, result.DescriptionHTML, result.BlockHTML} {
+		if !utf8.ValidString(s) {
+			t.Errorf("invalid UTF-8 in result %q: %q", result.URL, s)
+		}
+	}
+}
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/serp")
+
+// golden is the recorded outcome of parsing one page.
+type golden struct {
+	Results  []SearchResult `json:"results"`
+	Metadata *SERPMetadata  `json:"metadata"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// TestParseGolden parses every page of testdata/serp and compares the
+// outcome to page.golden.json. After an intended parser change, rewrite
+// the golden files with
+//
+//	go test -run TestParseGolden -update
+//
+// and review the difference with git diff.
+func TestParseGolden(t *testing.T) {
+	tests := []struct {
+		page        string
+		wantErr     error
+		wantResults int
+	}{
+		{page: "desktop", wantResults: 3},
+		{page: "mobile", wantResults: 3},
+		{page: "lite", wantResults: 3},
+		{page: "consent"},
+		{page: "captcha", wantErr: ErrBlocked},
+		{page: "noresults"},
+	}
+
+	pages, err := filepath.Glob(filepath.Join("testdata", "serp", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != len(tests) {
+		t.Errorf("testdata/serp holds %d pages, the table covers %d", len(pages), len(tests))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.page, func(t *testing.T) {
+			base := filepath.Join("testdata", "serp", tt.page)
+			var (
+				g   golden
+				err error
+			)
+			g.Results, g.Metadata, err = ParseFile(base + ".html")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseFile error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				g.Error = err.Error()
+			}
+			if len(g.Results) != tt.wantResults {
+				t.Errorf("got %d results, want %d", len(g.Results), tt.wantResults)
+			}
+
+			got, err := json.MarshalIndent(g, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+			path := base + ".golden.json"
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s differs from the parser output at %s", path, firstDifference(want, got))
+			}
+		})
+	}
+}
+
+// featureTests check the answer boxes and rich results parsed from the
+// pages of testdata/features, each made for one extractor.
+var featureTests = []struct {
+	page  string
+	check func(t *testing.T, results []SearchResult, m *SERPMetadata)
+}{
+	{"currency", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		want := &CurrencyAnswer{FromAmount: 100, FromCurrency: "US Dollar", ToAmount: 92.14, ToCurrency: "Euro",
+			Text: "100 United States Dollar equals 92.14 Euro"}
+		if !reflect.DeepEqual(m.Currency, want) || m.Unit != nil {
+			t.Errorf("Currency = %+v, Unit = %+v, want %+v", m.Currency, m.Unit, want)
+		}
+	}},
+	{"unit", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		want := &UnitAnswer{FromValue: 5, FromUnit: "Mile", ToValue: 8.04672, ToUnit: "Kilometre", Text: "5 Mile = 8.04672 Kilometre"}
+		if !reflect.DeepEqual(m.Unit, want) || m.Currency != nil {
+			t.Errorf("Unit = %+v, Currency = %+v, want %+v", m.Unit, m.Currency, want)
+		}
+	}},
+	{"conversion", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		want := &CurrencyAnswer{FromAmount: 1000, FromCurrency: "Japanese yen", ToAmount: 6.71, ToCurrency: "US dollars",
+			Text: "1,000 Japanese yen = 6.71 US dollars"}
+		if !reflect.DeepEqual(m.Currency, want) {
+			t.Errorf("Currency = %+v, want %+v", m.Currency, want)
+		}
+	}},
+	{"weather", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		want := &WeatherAnswer{
+			Location: "Berlin, Germany", Time: "Friday 14:00", Temperature: "18", Unit: "°C",
+			Condition: "Partly cloudy", Precipitation: "10%", Humidity: "62%", Wind: "14 km/h",
+			Forecast: []WeatherForecastDay{
+				{Day: "Friday", Condition: "Partly cloudy", High: "20", Low: "11"},
+				{Day: "Saturday", Condition: "Rain", High: "16", Low: "9"},
+			},
+		}
+		if !reflect.DeepEqual(m.Weather, want) {
+			t.Errorf("Weather = %+v, want %+v", m.Weather, want)
+		}
+	}},
+	{"dictionary", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		// The adjective heading has no definitions and is dropped.
+		want := []DictionaryEntry{{
+			Word: "ser·en·dip·i·ty", Phonetic: "/ˌserənˈdipədē/", PartOfSpeech: "noun",
+			Definitions: []DictionaryDefinition{
+				{Text: "the occurrence of events by chance in a happy way.", Examples: []string{"a fortunate stroke of serendipity"}},
+				{Text: "a pleasant surprise."},
+			},
+		}}
+		if !reflect.DeepEqual(m.Definitions, want) {
+			t.Errorf("Definitions = %+v, want %+v", m.Definitions, want)
+		}
+	}},
+	{"sports", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		want := []SportsGame{
+			{League: "Premier League", HomeTeam: "Arsenal", AwayTeam: "Chelsea", HomeScore: "2", AwayScore: "1", State: "Final"},
+			{League: "NBA", HomeTeam: "Lakers", AwayTeam: "Celtics", HomeScore: "101", AwayScore: "99", State: "Live"},
+			{League: "NBA", HomeTeam: "Bulls", AwayTeam: "Knicks", State: "Tomorrow, 7:30 PM"},
+		}
+		if !reflect.DeepEqual(m.Sports, want) {
+			t.Errorf("Sports = %+v, want %+v", m.Sports, want)
+		}
+	}},
+	{"flights", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		// The "Show flights" link has a single part and is not an option.
+		want := &FlightsBox{Route: "London to New York", Options: []FlightOption{
+			{Airline: "British Airways", Stops: "Nonstop", Duration: "7h 55m", Price: "£412",
+				URL: "https://www.google.com/travel/flights?q=LHR+JFK&airline=BA"},
+			{Airline: "American", Stops: "1 stop", Duration: "10h 20m", Price: "from £356",
+				URL: "https://www.google.com/travel/flights?q=LHR+JFK&airline=AA"},
+		}}
+		if !reflect.DeepEqual(m.Flights, want) {
+			t.Errorf("Flights = %+v, want %+v", m.Flights, want)
+		}
+	}},
+	{"events", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		want := []Event{
+			{Name: "Jazz Night", Date: "Fri, Nov 6, 8 PM", Venue: "A-Trane, Berlin", URL: "https://www.google.com/search?q=jazz+night&ibp=htl;events"},
+			{Name: "Opera Gala", Date: "14 Nov", Venue: "Staatsoper", URL: "https://tickets.example.com/opera"},
+		}
+		if !reflect.DeepEqual(m.Events, want) {
+			t.Errorf("Events = %+v, want %+v", m.Events, want)
+		}
+	}},
+	{"jobs", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		want := []Job{
+			{Title: "Senior Go Developer", Company: "Example GmbH", Location: "Berlin, Germany", Posted: "3 days ago", Via: "LinkedIn",
+				URL: "https://www.google.com/search?q=go+developer&ibp=htl;jobs#job1"},
+			{Title: "Backend Engineer (Go)", Company: "Acme", Location: "Remote", Via: "Indeed", URL: "https://jobs.example.org/42"},
+		}
+		if !reflect.DeepEqual(m.Jobs, want) {
+			t.Errorf("Jobs = %+v, want %+v", m.Jobs, want)
+		}
+	}},
+	{"recipes", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		want := []RecipeResult{
+			{Title: "Best Banana Bread", URL: "https://recipes.example.com/banana-bread", Source: "Example Recipes",
+				Rating: 4.8, Reviews: "2.1K", CookTime: "1 hr 10 min", Thumbnail: "https://recipes.example.com/bread.jpg"},
+			{Title: "Vegan Banana Bread", URL: "https://bake.example.org/vegan-banana-bread", Source: "Bake Club",
+				Rating: 4.5, CookTime: "55 mins"},
+		}
+		if !reflect.DeepEqual(m.Recipes, want) {
+			t.Errorf("Recipes = %+v, want %+v", m.Recipes, want)
+		}
+	}},
+	{"social", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		// Only sections headed by an X profile are social posts.
+		want := []SocialPost{
+			{Author: "Go (@golang) · X", Text: "Go 1.23 is released! Range over functions, iterators and more.",
+				Timestamp: "2 days ago", URL: "https://twitter.com/golang/status/1001"},
+			{Author: "Go (@golang) · X", Text: "GopherCon talks are online.", Timestamp: "1w", URL: "https://twitter.com/golang/status/1002"},
+		}
+		if !reflect.DeepEqual(m.SocialPosts, want) {
+			t.Errorf("SocialPosts = %+v, want %+v", m.SocialPosts, want)
+		}
+	}},
+	{"videos", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		want := []Video{
+			{Title: "Learn Go in 3 Hours", URL: "https://www.youtube.com/watch?v=abc123", Platform: "YouTube",
+				Channel: "Gopher Academy", Duration: "3:04:11", KeyMoments: []VideoKeyMoment{
+					{Time: "1:35", Label: "Installing Go", URL: "https://www.youtube.com/watch?v=abc123&t=95"},
+					{Time: "600", Label: "Goroutines", URL: "https://www.youtube.com/watch?v=abc123&t=600"},
+				}},
+			{Title: "Go Concurrency Patterns", URL: "https://vimeo.com/12345", Platform: "Vimeo", Duration: "51:27"},
+		}
+		if !reflect.DeepEqual(m.Videos, want) {
+			t.Errorf("Videos = %+v, want %+v", m.Videos, want)
+		}
+	}},
+	{"images", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		// Inline data: placeholders are no thumbnail, and links without an
+		// image are skipped.
+		want := []InlineImage{
+			{Thumbnail: "https://encrypted-tbn0.gstatic.com/images?q=tbn:1", ImageURL: "https://img.example.com/gopher.png",
+				SourceURL: "https://blog.example.com/gopher", Title: "The Go gopher"},
+			{SourceURL: "https://art.example.org/gophers", Title: "Gopher art"},
+		}
+		if !reflect.DeepEqual(m.Images, want) {
+			t.Errorf("Images = %+v, want %+v", m.Images, want)
+		}
+	}},
+	{"localpack", func(t *testing.T, _ []SearchResult, m *SERPMetadata) {
+		// Identifiers come from data attributes or, failing those, the
+		// Maps link, whose !3d!4d pin wins over its @ viewport.
+		want := []LocalResult{
+			{Name: "Bonanza Coffee", Rating: 4.6, Reviews: "1,204",
+				Details: []string{"4.6(1,204)", "Coffee shop · Oderberger Str. 35", "Open · Closes 6 PM"},
+				URL:     "https://www.bonanzacoffee.example/", CID: "1234567890123456789", Latitude: 52.52, Longitude: 13.405},
+			{Name: "The Barn", Rating: 4.4, Details: []string{"4,4"},
+				URL: "https://www.google.com/maps/place/The+Barn/@52.5302,13.4011,17z/data=!3m1!4b1!4m5!3m4!1s0x47a851e3:0x1f2b!8m2!3d52.5301!4d13.4012?ludocid=987654321&place_id=ChIJabc",
+				CID: "987654321", FeatureID: "0x47a851e3:0x1f2b", PlaceID: "ChIJabc", Latitude: 52.5301, Longitude: 13.4012},
+		}
+		if !reflect.DeepEqual(m.LocalPack, want) {
+			t.Errorf("LocalPack = %+v, want %+v", m.LocalPack, want)
+		}
+	}},
+	{"amp", func(t *testing.T, results []SearchResult, _ *SERPMetadata) {
+		type amp struct{ URL, AMPURL, CanonicalURL string }
+		want := []amp{
+			{"https://news.example.com/story", "https://news-example-com.cdn.ampproject.org/c/s/news.example.com/story/amp", "https://news.example.com/story"},
+			{"https://blog-example-org.cdn.ampproject.org/c/s/blog.example.org/post", "https://blog-example-org.cdn.ampproject.org/c/s/blog.example.org/post", "https://blog.example.org/post"},
+			{"https://www.example.net/article/amp", "https://www.example.net/article/amp", "https://www.example.net/article"},
+			{"https://plain.example.com/page", "", ""},
+		}
+		var got []amp
+		for _, result := range results {
+			got = append(got, amp{result.URL, result.AMPURL, result.CanonicalURL})
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("AMP URLs = %+v, want %+v", got, want)
+		}
+	}},
+	{"translated", func(t *testing.T, results []SearchResult, _ *SERPMetadata) {
+		if len(results) != 2 {
+			t.Fatalf("got %d results, want 2", len(results))
+		}
+		got := results[0]
+		if got.OriginalTitle != "Der beste Kaffee in Berlin" || got.OriginalLanguage != "de" ||
+			got.TranslationURL != "https://translate.google.com/translate?sl=de&tl=en&u=https://kaffee.example.de/berlin" {
+			t.Errorf("translated result = %q (%q) via %q", got.OriginalTitle, got.OriginalLanguage, got.TranslationURL)
+		}
+		if other := results[1]; other.OriginalTitle != "" || other.TranslationURL != "" {
+			t.Errorf("untranslated result has original title %q and translation %q", other.OriginalTitle, other.TranslationURL)
+		}
+	}},
+}
+
+func TestParseFeatures(t *testing.T) {
+	pages, err := filepath.Glob(filepath.Join("testdata", "features", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != len(featureTests) {
+		t.Errorf("testdata/features holds %d pages, the table covers %d", len(pages), len(featureTests))
+	}
+	for _, tt := range featureTests {
+		t.Run(tt.page, func(t *testing.T) {
+			results, metadata, err := ParseFile(filepath.Join("testdata", "features", tt.page+".html"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, results, metadata)
+		})
+	}
+}
+
+// firstDifference describes the first line where want and got differ.
+func firstDifference(want, got []byte) string {
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d: want %q, got %q", i+1, strings.TrimSpace(w), strings.TrimSpace(g))
+		}
+	}
+	return "no difference"
+}
+
+// allocBudgets are the allowed allocations per parsed page, about 40%
+// over the values measured when they were set (in the comments). Raise
+// them only with a reason.
+var allocBudgets = map[string]float64{
+	"lite":       1750,  // 1272
+	"desktop":    2000,  // 1444
+	"mobile":     1550,  // 1132
+	"large-page": 20500, // 15023
+}
+
+// largeResults is the number of results on the synthetic large page, the
+// most a num=100 request returns.
+const largeResults = 100
+
+// benchPage returns the named page of testdata/serp, or for "large-page"
+// the lite page with its organic results repeated until it holds
+// largeResults of them.
+func benchPage(tb testing.TB, name string) []byte {
+	tb.Helper()
+	if name != "large-page" {
+		data, err := os.ReadFile(filepath.Join("testdata", "serp", name+".html"))
+		if err != nil {
+			tb.Fatal(err)
+		}
+		return data
+	}
+	page := string(benchPage(tb, "lite"))
+	first := strings.Index(page, `<div class="ezO2md">`)
+	end := strings.Index(page, `<div class="gGQDvd">`)
+	if first < 0 || end < first {
+		tb.Fatal("lite page has no results to repeat")
+	}
+	block := page[first:end]
+	perBlock := strings.Count(block, `class="ezO2md"`)
+
+	var b strings.Builder
+	b.WriteString(page[:first])
+	for i := 0; i*perBlock < largeResults; i++ {
+		b.WriteString(strings.ReplaceAll(block, "example", fmt.Sprintf("example%d", i)))
+	}
+	b.WriteString(page[end:])
+	return []byte(b.String())
+}
+
+func benchmarkParse(b *testing.B, name string) {
+	data := benchPage(b, name)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if _, _, err := ParseHTML(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseLite(b *testing.B)      { benchmarkParse(b, "lite") }
+func BenchmarkParseDesktop(b *testing.B)   { benchmarkParse(b, "desktop") }
+func BenchmarkParseMobile(b *testing.B)    { benchmarkParse(b, "mobile") }
+func BenchmarkParseLargePage(b *testing.B) { benchmarkParse(b, "large-page") }
+
+func BenchmarkExtractResult(b *testing.B) {
+	doc, err := newDocument(bytes.NewReader(benchPage(b, "large-page")))
+	if err != nil {
+		b.Fatal(err)
+	}
+	selectors := CurrentSelectors()
+	blocks := doc.Find(selectors.Result)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		blocks.Each(func(_ int, s *goquery.Selection) {
+			extractResult(s, doc.Url, selectors, SearchOptions{})
+		})
+	}
+}
+
+// TestParseAllocBudget fails when parsing a page allocates more than its
+// budget in allocBudgets.
+func TestParseAllocBudget(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are inflated by the race detector")
+	}
+	for name, budget := range allocBudgets {
+		t.Run(name, func(t *testing.T) {
+			data := benchPage(t, name)
+			allocs := testing.AllocsPerRun(20, func() {
+				if _, _, err := ParseHTML(bytes.NewReader(data)); err != nil {
+					t.Fatal(err)
+				}
+			})
+			if allocs > budget {
+				t.Errorf("parsing %s takes %.0f allocs, over the budget of %.0f", name, allocs, budget)
+			}
+		})
+	}
+}
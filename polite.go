@@ -0,0 +1,45 @@
+package googlesearch
+
+import "time"
+
+// politeUserAgentPages is how many pages NewPoliteSearcher requests with
+// one user agent.
+const politeUserAgentPages = 10
+
+// NewPoliteSearcher returns a Searcher with conservative defaults for
+// anyone getting started, so a few hundred searches do not get the IP
+// blocked. Unless opts sets them already:
+//
+//   - pages are spaced with the jittered delays of PacingNormal,
+//   - a fresh session with a new user agent is started every ten pages
+//     (UserAgentPages), unless RotateUserAgent or UserAgent is set,
+//   - an AdaptiveThrottle backs off after captchas and 429s,
+//   - failed pages are retried twice starting after 30s, and
+//   - the session is warmed up on the homepage first; set DisableWarmUp
+//     to skip it.
+func NewPoliteSearcher(opts SearchOptions) (*Searcher, error) {
+	return NewSearcher(politeOptions(opts))
+}
+
+func politeOptions(opts SearchOptions) SearchOptions {
+	if opts.Pacing == nil && opts.SleepInterval == 0 {
+		pacing := PacingNormal
+		opts.Pacing = &pacing
+	}
+	if opts.UserAgentPages == 0 && !opts.RotateUserAgent && opts.UserAgent == "" {
+		opts.UserAgentPages = politeUserAgentPages
+	}
+	if opts.Throttle == nil {
+		opts.Throttle = &AdaptiveThrottle{}
+	}
+	if opts.Retries == 0 {
+		opts.Retries = 2
+	}
+	if opts.RetryBackoff == 0 {
+		opts.RetryBackoff = 30 * time.Second
+	}
+	if !opts.DisableWarmUp {
+		opts.WarmUp = true
+	}
+	return opts
+}
@@ -0,0 +1,56 @@
+package googlesearch
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPoliteOptions(t *testing.T) {
+	opts := politeOptions(SearchOptions{})
+	if opts.Pacing == nil || opts.Throttle == nil || opts.Retries != 2 || !opts.WarmUp {
+		t.Errorf("polite defaults missing: %+v", opts)
+	}
+	if opts.UserAgentPages != politeUserAgentPages {
+		t.Errorf("UserAgentPages = %d, want %d", opts.UserAgentPages, politeUserAgentPages)
+	}
+
+	opts = politeOptions(SearchOptions{DisableWarmUp: true, RotateUserAgent: true})
+	if opts.WarmUp {
+		t.Error("DisableWarmUp did not turn the polite warm-up off")
+	}
+	if opts.UserAgentPages != 0 {
+		t.Errorf("UserAgentPages = %d with RotateUserAgent, want 0", opts.UserAgentPages)
+	}
+}
+
+func TestUserAgentPagesRotatesSession(t *testing.T) {
+	lite := servePage(t, "lite")
+	var warmUps atomic.Int64
+	google := newFakeGoogleFunc(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			warmUps.Add(1)
+		}
+		lite(w, r)
+	})
+	proxy := newFakeProxy(t, google)
+	s, err := NewSearcher(SearchOptions{
+		Proxy:              proxy.URL,
+		InsecureSkipVerify: true,
+		WarmUp:             true,
+		UserAgentPages:     2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := s.FetchSERP(context.Background(), "golang", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Pages 1-2, 3-4 and 5 each get a fresh, warmed up session.
+	if got := warmUps.Load(); got != 3 {
+		t.Errorf("%d warm-ups for 5 pages at 2 pages per user agent, want 3", got)
+	}
+}
@@ -0,0 +1,91 @@
+package googlesearch
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Preset bundles the settings that must agree with each other to look like
+// one consistent visitor: the browser user agent, from which the client
+// hints and Accept header are derived, the interface language and country
+// (hl/gl, which also drive Accept-Language) and the Google domain.
+type Preset struct {
+	Name      string
+	UserAgent string
+	Lang      Language
+	Region    Region
+	Domain    string
+}
+
+const (
+	uaWindowsChrome = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	uaMacSafari     = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15"
+	uaLinuxFirefox  = "Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0"
+	uaAndroidChrome = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36"
+	uaIPhoneSafari  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+)
+
+var presets = map[string]Preset{}
+
+func init() {
+	for _, p := range []Preset{
+		{"us-desktop-chrome", uaWindowsChrome, "en", "us", "google.com"},
+		{"us-desktop-safari", uaMacSafari, "en", "us", "google.com"},
+		{"us-mobile-android", uaAndroidChrome, "en", "us", "google.com"},
+		{"us-mobile-iphone", uaIPhoneSafari, "en", "us", "google.com"},
+		{"uk-desktop-chrome", uaWindowsChrome, "en-GB", "gb", "google.co.uk"},
+		{"uk-mobile-iphone", uaIPhoneSafari, "en-GB", "gb", "google.co.uk"},
+		{"ca-desktop-chrome", uaWindowsChrome, "en", "ca", "google.ca"},
+		{"au-desktop-chrome", uaWindowsChrome, "en", "au", "google.com.au"},
+		{"in-mobile-android", uaAndroidChrome, "en", "in", "google.co.in"},
+		{"de-desktop-chrome", uaWindowsChrome, "de", "de", "google.de"},
+		{"de-desktop-firefox", uaLinuxFirefox, "de", "de", "google.de"},
+		{"de-mobile-android", uaAndroidChrome, "de", "de", "google.de"},
+		{"fr-desktop-chrome", uaWindowsChrome, "fr", "fr", "google.fr"},
+		{"fr-mobile-iphone", uaIPhoneSafari, "fr", "fr", "google.fr"},
+		{"es-desktop-chrome", uaWindowsChrome, "es", "es", "google.es"},
+		{"it-desktop-chrome", uaWindowsChrome, "it", "it", "google.it"},
+		{"nl-desktop-chrome", uaWindowsChrome, "nl", "nl", "google.nl"},
+		{"br-mobile-android", uaAndroidChrome, "pt-BR", "br", "google.com.br"},
+		{"jp-mobile-iphone", uaIPhoneSafari, "ja", "jp", "google.co.jp"},
+	} {
+		presets[p.Name] = p
+	}
+}
+
+// PresetByName returns one of the built-in presets, such as
+// "us-desktop-chrome" or "de-mobile-android".
+func PresetByName(name string) (Preset, error) {
+	p, ok := presets[name]
+	if !ok {
+		return Preset{}, fmt.Errorf("google: unknown preset: %q", name)
+	}
+	return p, nil
+}
+
+// PresetNames lists the built-in presets in alphabetical order.
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply fills the fields of opts that are still unset from p.
+func (p Preset) apply(opts SearchOptions) SearchOptions {
+	if opts.UserAgent == "" {
+		opts.UserAgent = p.UserAgent
+	}
+	if opts.Lang == "" {
+		opts.Lang = p.Lang
+	}
+	if opts.Region == "" {
+		opts.Region = p.Region
+	}
+	if opts.Domain == "" {
+		opts.Domain = p.Domain
+	}
+	return opts
+}
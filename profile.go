@@ -0,0 +1,54 @@
+package googlesearch
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// fetchProfile controls how a results page is requested. Google sometimes
+// serves an experimental layout the parser does not understand to one
+// client profile only, so an empty page is retried with another profile.
+type fetchProfile struct {
+	name      string
+	userAgent func() string
+	params    url.Values
+}
+
+var (
+	defaultProfile = fetchProfile{
+		name:      "default",
+		userAgent: getRandomUserAgent,
+	}
+	// liteProfile requests the basic HTML layout served to text browsers.
+	liteProfile = fetchProfile{
+		name:      "lite",
+		userAgent: GetCustomUserAgent,
+		params:    url.Values{"gbv": {"1"}},
+	}
+)
+
+func (p fetchProfile) alternate() fetchProfile {
+	if p.name == liteProfile.name {
+		return defaultProfile
+	}
+	return liteProfile
+}
+
+// isBlocked reports whether Google answered with its captcha interstitial
+// instead of results.
+func isBlocked(resp *http.Response, doc *goquery.Document) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if resp.Request != nil && strings.HasPrefix(resp.Request.URL.Path, "/sorry/") {
+		return true
+	}
+	return doc != nil && isCaptchaPage(doc)
+}
+
+func isCaptchaPage(doc *goquery.Document) bool {
+	return doc.Find("form#captcha-form, div#recaptcha, div.g-recaptcha").Length() > 0
+}
@@ -0,0 +1,49 @@
+package googlesearch
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ProxyProvider hands out the proxy to use for each outgoing request.
+// Next returns the proxy URL (nil for a direct connection) and a release
+// function the library calls once the request has finished, reporting
+// whether it succeeded so rotating services can retire bad exits.
+type ProxyProvider interface {
+	Next(ctx context.Context) (proxy *url.URL, release func(success bool))
+}
+
+type staticProxyProvider struct {
+	proxy *url.URL
+}
+
+// NewStaticProxyProvider returns a ProxyProvider that always uses the given
+// proxy. An empty string yields a provider for direct connections.
+func NewStaticProxyProvider(proxy string) (ProxyProvider, error) {
+	if proxy == "" {
+		return staticProxyProvider{}, nil
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	return staticProxyProvider{proxy: proxyURL}, nil
+}
+
+func (p staticProxyProvider) Next(ctx context.Context) (*url.URL, func(bool)) {
+	return p.proxy, nil
+}
+
+type proxyContextKey struct{}
+
+func withProxy(ctx context.Context, proxy *url.URL) context.Context {
+	return context.WithValue(ctx, proxyContextKey{}, proxy)
+}
+
+func proxyFromContext(req *http.Request) (*url.URL, error) {
+	if proxy, ok := req.Context().Value(proxyContextKey{}).(*url.URL); ok && proxy != nil {
+		return proxy, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
@@ -0,0 +1,79 @@
+package googlesearch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ParseProxyList reads a newline-delimited proxy list. Blank lines and
+// lines starting with # are skipped. Each entry is a proxy URL
+// (http, https, socks5 or socks5h, optionally with credentials), a bare
+// host:port, which is taken as HTTP, or host:port:user:password as
+// exported by many proxy vendors.
+func ParseProxyList(r io.Reader) ([]string, error) {
+	var proxies []string
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		entry := strings.TrimSpace(sc.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		proxy, err := normalizeProxyEntry(entry)
+		if err != nil {
+			return nil, fmt.Errorf("google: proxy list line %d: %w", line, err)
+		}
+		proxies = append(proxies, proxy)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return proxies, nil
+}
+
+// LoadProxyFile reads the proxy list at path into a new ProxyPool.
+func LoadProxyFile(path string) (*ProxyPool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	proxies, err := ParseProxyList(f)
+	if err != nil {
+		return nil, err
+	}
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("google: no proxies in %s", path)
+	}
+	return NewProxyPool(proxies...)
+}
+
+func normalizeProxyEntry(entry string) (string, error) {
+	if !strings.Contains(entry, "://") {
+		if parts := strings.Split(entry, ":"); len(parts) == 4 {
+			entry = fmt.Sprintf("%s:%s@%s:%s",
+				url.PathEscape(parts[2]), url.PathEscape(parts[3]), parts[0], parts[1])
+		}
+		entry = "http://" + entry
+	}
+
+	u, err := url.Parse(entry)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return "", fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil || host == "" || port == "" {
+		return "", fmt.Errorf("proxy %q needs host:port", u.Redacted())
+	}
+	return u.String(), nil
+}
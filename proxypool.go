@@ -0,0 +1,211 @@
+package googlesearch
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ProxyBlockReporter can be implemented by a ProxyProvider that wants to
+// know when one of its proxies was served a captcha, in addition to the
+// success flag passed to the release function.
+type ProxyBlockReporter interface {
+	ReportBlocked(proxy *url.URL)
+}
+
+// ProxySkipReporter can be implemented by a ProxyProvider that wants to
+// know when a proxy it handed out was skipped unused because it is cooling
+// down in the BlockRegistry. The release function of a skipped proxy is
+// never called.
+type ProxySkipReporter interface {
+	ReportSkipped(proxy *url.URL)
+}
+
+// ProxyStats are the metrics a ProxyPool keeps per proxy.
+type ProxyStats struct {
+	Proxy       string
+	Requests    int
+	Successes   int
+	Failures    int
+	Captchas    int
+	SuccessRate float64
+	CaptchaRate float64
+	AvgLatency  time.Duration
+	// LastUsed is when the latest finished request through the proxy
+	// started.
+	LastUsed    time.Time
+	LastBlocked time.Time
+	// Score combines success and captcha rates into a value between 0 and
+	// 1, smoothed so proxies without history start at 0.5.
+	Score float64
+}
+
+type pooledProxy struct {
+	url          *url.URL
+	requests     int
+	successes    int
+	failures     int
+	captchas     int
+	totalLatency time.Duration
+	lastUsed     time.Time
+	lastBlocked  time.Time
+}
+
+func (p *pooledProxy) stats() ProxyStats {
+	stats := ProxyStats{
+		Proxy:       p.url.Redacted(),
+		Requests:    p.requests,
+		Successes:   p.successes,
+		Failures:    p.failures,
+		Captchas:    p.captchas,
+		LastUsed:    p.lastUsed,
+		LastBlocked: p.lastBlocked,
+	}
+	if finished := p.successes + p.failures; finished > 0 {
+		stats.SuccessRate = float64(p.successes) / float64(finished)
+		stats.AvgLatency = p.totalLatency / time.Duration(finished)
+	}
+	if p.requests > 0 {
+		stats.CaptchaRate = float64(p.captchas) / float64(p.requests)
+	}
+	stats.Score = float64(p.successes+1) / float64(p.successes+p.failures+2) *
+		(1 - float64(p.captchas)/float64(p.requests+1))
+	return stats
+}
+
+// ProxyPool is a round-robin ProxyProvider over a fixed list of proxies
+// that records per-proxy statistics. It is safe for concurrent use.
+type ProxyPool struct {
+	mu      sync.Mutex
+	proxies []*pooledProxy
+	next    int
+}
+
+func NewProxyPool(proxies ...string) (*ProxyPool, error) {
+	pool := &ProxyPool{}
+	for _, proxy := range proxies {
+		if err := pool.Add(proxy); err != nil {
+			return nil, err
+		}
+	}
+	return pool, nil
+}
+
+// Add appends proxy to the rotation.
+func (p *ProxyPool) Add(proxy string) error {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return err
+	}
+	if proxyURL.Host == "" {
+		return errors.New("google: proxy URL without host: " + proxy)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.proxies = append(p.proxies, &pooledProxy{url: proxyURL})
+	return nil
+}
+
+func (p *ProxyPool) Next(ctx context.Context) (*url.URL, func(bool)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.proxies) == 0 {
+		return nil, nil
+	}
+
+	proxy := p.proxies[p.next%len(p.proxies)]
+	p.next++
+	proxy.requests++
+
+	started := time.Now()
+	var once sync.Once
+	return proxy.url, func(success bool) {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			// Set here rather than in Next, so a proxy skipped with
+			// ReportSkipped is not reported as used.
+			if started.After(proxy.lastUsed) {
+				proxy.lastUsed = started
+			}
+			proxy.totalLatency += time.Since(started)
+			if success {
+				proxy.successes++
+			} else {
+				proxy.failures++
+			}
+		})
+	}
+}
+
+func (p *ProxyPool) ReportBlocked(proxy *url.URL) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if pooled := p.find(proxy.String()); pooled != nil {
+		pooled.captchas++
+		pooled.lastBlocked = time.Now()
+	}
+}
+
+// ReportSkipped takes back the request counted when proxy was handed out.
+func (p *ProxyPool) ReportSkipped(proxy *url.URL) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if pooled := p.find(proxy.String()); pooled != nil && pooled.requests > 0 {
+		pooled.requests--
+	}
+}
+
+// Stats returns a snapshot of the metrics of every proxy in the pool.
+func (p *ProxyPool) Stats() []ProxyStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make([]ProxyStats, 0, len(p.proxies))
+	for _, proxy := range p.proxies {
+		stats = append(stats, proxy.stats())
+	}
+	return stats
+}
+
+// Remove drops proxy from the rotation and reports whether it was present.
+func (p *ProxyPool) Remove(proxy string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, pooled := range p.proxies {
+		if pooled.url.String() == proxy || pooled.url.Redacted() == proxy {
+			p.proxies = append(p.proxies[:i], p.proxies[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Prune removes proxies with at least minRequests requests whose Score is
+// below minScore and returns the removed entries.
+func (p *ProxyPool) Prune(minScore float64, minRequests int) []ProxyStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var removed []ProxyStats
+	kept := p.proxies[:0]
+	for _, pooled := range p.proxies {
+		stats := pooled.stats()
+		if pooled.requests >= minRequests && stats.Score < minScore {
+			removed = append(removed, stats)
+			continue
+		}
+		kept = append(kept, pooled)
+	}
+	p.proxies = kept
+	return removed
+}
+
+func (p *ProxyPool) find(proxy string) *pooledProxy {
+	for _, pooled := range p.proxies {
+		if pooled.url.String() == proxy {
+			return pooled
+		}
+	}
+	return nil
+}
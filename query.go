@@ -0,0 +1,96 @@
+package googlesearch
+
+import (
+	"strings"
+	"time"
+)
+
+// QueryBuilder composes a query string from search operators, quoting
+// values where Google needs it.
+//
+//	q := NewQuery("release notes").Site("go.dev").After(t).String()
+type QueryBuilder struct {
+	parts []string
+}
+
+// NewQuery starts a query with the given free text terms.
+func NewQuery(terms string) *QueryBuilder {
+	q := &QueryBuilder{}
+	if terms = strings.TrimSpace(terms); terms != "" {
+		q.parts = append(q.parts, terms)
+	}
+	return q
+}
+
+func (q *QueryBuilder) add(op, value string) *QueryBuilder {
+	if value = strings.TrimSpace(value); value != "" {
+		q.parts = append(q.parts, op+quoteOperand(value))
+	}
+	return q
+}
+
+// Exact requires the exact phrase.
+func (q *QueryBuilder) Exact(phrase string) *QueryBuilder {
+	if phrase = strings.TrimSpace(phrase); phrase != "" {
+		q.parts = append(q.parts, `"`+strings.ReplaceAll(phrase, `"`, "")+`"`)
+	}
+	return q
+}
+
+// Exclude drops results containing term.
+func (q *QueryBuilder) Exclude(term string) *QueryBuilder { return q.add("-", term) }
+
+// Site restricts results to a domain or URL prefix.
+func (q *QueryBuilder) Site(domain string) *QueryBuilder { return q.add("site:", domain) }
+
+// FileType restricts results to documents with the given extension.
+func (q *QueryBuilder) FileType(ext string) *QueryBuilder {
+	return q.add("filetype:", strings.TrimPrefix(ext, "."))
+}
+
+// InTitle requires term in the page title.
+func (q *QueryBuilder) InTitle(term string) *QueryBuilder { return q.add("intitle:", term) }
+
+// InURL requires term in the URL.
+func (q *QueryBuilder) InURL(term string) *QueryBuilder { return q.add("inurl:", term) }
+
+// Before and After restrict results by publication date.
+func (q *QueryBuilder) Before(t time.Time) *QueryBuilder {
+	return q.add("before:", t.Format("2006-01-02"))
+}
+
+func (q *QueryBuilder) After(t time.Time) *QueryBuilder {
+	return q.add("after:", t.Format("2006-01-02"))
+}
+
+// Literal appends text as plain search terms, quoting the words Google
+// would otherwise read as operators, such as "-v", "OR" or "site:x".
+func (q *QueryBuilder) Literal(text string) *QueryBuilder {
+	for _, word := range strings.Fields(text) {
+		if isOperatorWord(word) {
+			word = `"` + strings.ReplaceAll(word, `"`, "") + `"`
+		}
+		q.parts = append(q.parts, word)
+	}
+	return q
+}
+
+// Raw appends s verbatim.
+func (q *QueryBuilder) Raw(s string) *QueryBuilder {
+	if s = strings.TrimSpace(s); s != "" {
+		q.parts = append(q.parts, s)
+	}
+	return q
+}
+
+func (q *QueryBuilder) String() string {
+	return strings.Join(q.parts, " ")
+}
+
+// quoteOperand quotes operator values containing spaces.
+func quoteOperand(s string) string {
+	if strings.ContainsAny(s, " \t") {
+		return `"` + strings.ReplaceAll(s, `"`, "") + `"`
+	}
+	return s
+}
@@ -0,0 +1,5 @@
+//go:build race
+
+package googlesearch
+
+const raceEnabled = true
@@ -0,0 +1,85 @@
+package googlesearch
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Scorer rates a result; position is its 1-based position in the input
+// to ReRankBy.
+type Scorer func(result SearchResult, position int) float64
+
+// ReRankBy returns a ReRank function that orders results by the summed
+// score of scorers, highest first. Results with equal scores keep their
+// original order.
+func ReRankBy(scorers ...Scorer) func([]SearchResult) []SearchResult {
+	return func(results []SearchResult) []SearchResult {
+		scores := make([]float64, len(results))
+		for i, result := range results {
+			for _, score := range scorers {
+				scores[i] += score(result, i+1)
+			}
+		}
+		order := make([]int, len(results))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+		ranked := make([]SearchResult, len(results))
+		for i, j := range order {
+			ranked[i] = results[j]
+		}
+		return ranked
+	}
+}
+
+// PositionScorer preserves Google's ranking as a signal, scoring weight
+// for the first result and decaying with 1/position.
+func PositionScorer(weight float64) Scorer {
+	return func(result SearchResult, position int) float64 {
+		return weight / float64(position)
+	}
+}
+
+// DomainAuthorityScorer scores results by a weight per registrable domain,
+// e.g. {"wikipedia.org": 2, "pinterest.com": -1}. Unlisted domains score 0.
+func DomainAuthorityScorer(weights map[string]float64) Scorer {
+	normalized := make(map[string]float64, len(weights))
+	for domain, weight := range weights {
+		normalized[strings.ToLower(strings.TrimPrefix(domain, "www."))] = weight
+	}
+	return func(result SearchResult, position int) float64 {
+		u, err := url.Parse(result.URL)
+		if err != nil {
+			return 0
+		}
+		host := strings.ToLower(u.Hostname())
+		if weight, ok := normalized[strings.TrimPrefix(host, "www.")]; ok {
+			return weight
+		}
+		if domain, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+			return normalized[domain]
+		}
+		return 0
+	}
+}
+
+// KeywordInTitleScorer adds boost for every word of query found in the
+// result title, ignoring case.
+func KeywordInTitleScorer(query string, boost float64) Scorer {
+	terms := strings.Fields(strings.ToLower(query))
+	return func(result SearchResult, position int) float64 {
+		title := strings.ToLower(result.Title)
+		score := 0.0
+		for _, term := range terms {
+			if strings.Contains(title, term) {
+				score += boost
+			}
+		}
+		return score
+	}
+}
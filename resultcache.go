@@ -0,0 +1,109 @@
+package googlesearch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// defaultCacheTTL is used for cached searches when CacheTTL is zero.
+const defaultCacheTTL = 24 * time.Hour
+
+type cachedSERP struct {
+	Query    string
+	Results  []SearchResult
+	Metadata *SERPMetadata
+	StoredAt time.Time
+}
+
+// cachedSearch serves term from opts.Cache when possible and stores the
+// outcome of run otherwise. Cache failures are not fatal; the search just
+// runs uncached. Searches with a custom Extractor or a per-page ReRank are
+// never cached, as functions cannot be told apart in the key.
+func cachedSearch(ctx context.Context, term string, opts SearchOptions, run func() ([]SearchResult, *SERPMetadata, error)) ([]SearchResult, *SERPMetadata, error) {
+	if opts.Cache == nil || opts.Extractor != nil || opts.ReRank != nil && opts.ReRankPerPage {
+		return run()
+	}
+
+	key := resultCacheKey(term, opts)
+	if data, ok, err := opts.Cache.Get(ctx, key); err == nil && ok {
+		var entry cachedSERP
+		if json.Unmarshal(data, &entry) == nil && (opts.CacheMaxAge <= 0 || time.Since(entry.StoredAt) < opts.CacheMaxAge) {
+			if entry.Metadata == nil {
+				entry.Metadata = &SERPMetadata{}
+			}
+			entry.Metadata.CachedAt = entry.StoredAt
+			for i := range entry.Results {
+				entry.Results[i].CachedAt = entry.StoredAt
+			}
+			return entry.Results, entry.Metadata, nil
+		}
+	}
+
+	results, metadata, err := run()
+	if err != nil {
+		return results, metadata, err
+	}
+	data, err := json.Marshal(cachedSERP{Query: term, Results: results, Metadata: metadata, StoredAt: time.Now()})
+	if err == nil {
+		ttl := opts.CacheTTL
+		if ttl <= 0 {
+			ttl = defaultCacheTTL
+		}
+		opts.Cache.Set(ctx, key, data, ttl)
+	}
+	return results, metadata, nil
+}
+
+// resultCacheKey covers every option that changes which results a search
+// returns or what they and the metadata hold, except the functions, see
+// cachedSearch. New options that do must be added here.
+func resultCacheKey(term string, opts SearchOptions) string {
+	if opts.SafeSearch == SafeSearchDefault {
+		opts.SafeSearch, _ = ParseSafeSearch(opts.Safe)
+	}
+	data, _ := json.Marshal(struct {
+		Term              string
+		NumResults        int
+		Lang              Language
+		Region            Region
+		Location          string
+		Coordinates       *Coordinates
+		SafeSearch        SafeSearch
+		StartNum          int
+		Unique            bool
+		TranslatedResults bool
+		TimeRange         TimeRange
+		ExtraParams       map[string][]string
+		AllResults        bool
+		MaxPages          int
+		MinNewResults     int
+		FuzzyDedup        bool
+		KeepNonWebLinks   bool
+		ExpandMoreResults bool
+		NoPersonalization bool
+		Profile           string
+		StrictCount       bool
+		Domain            string
+		Preset            string
+		Normalize         TextNormalization
+		KeepHTML          bool
+		KeepBlockHTML     bool
+		FeatureSummary    bool
+		DomainSummary     bool
+		UserAgent         string
+		RotateUserAgent   bool
+	}{
+		term, opts.NumResults, opts.Lang, opts.Region, opts.Location, opts.Coordinates,
+		opts.SafeSearch, opts.StartNum, opts.Unique, opts.TranslatedResults, opts.TimeRange,
+		opts.ExtraParams, opts.AllResults, opts.MaxPages, opts.MinNewResultsPerPage, opts.FuzzyDedup,
+		opts.KeepNonWebLinks, opts.ExpandMoreResults, opts.DisablePersonalization, opts.profile,
+		opts.StrictCount, normalizeGoogleDomain(opts.Domain), opts.Preset,
+		opts.Normalize, opts.KeepHTML, opts.KeepBlockHTML, opts.FeatureSummaryOnly, opts.DomainSummary,
+		opts.UserAgent, opts.RotateUserAgent,
+	})
+	sum := sha256.Sum256(data)
+	return "serp:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,83 @@
+package googlesearch
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestResultCacheKeyCoversShapeOptions(t *testing.T) {
+	base := SearchOptions{NumResults: 10}
+	for name, opts := range map[string]SearchOptions{
+		"KeepHTML":           {NumResults: 10, KeepHTML: true},
+		"KeepBlockHTML":      {NumResults: 10, KeepBlockHTML: true},
+		"FeatureSummaryOnly": {NumResults: 10, FeatureSummaryOnly: true},
+		"DomainSummary":      {NumResults: 10, DomainSummary: true},
+		"Normalize":          {NumResults: 10, Normalize: TextNormalization{Whitespace: true}},
+		"UserAgent":          {NumResults: 10, UserAgent: "Mozilla/5.0 (iPhone)"},
+		"RotateUserAgent":    {NumResults: 10, RotateUserAgent: true},
+	} {
+		if resultCacheKey("golang", opts) == resultCacheKey("golang", base) {
+			t.Errorf("%s does not change the cache key", name)
+		}
+	}
+}
+
+func TestCachedSearchKeepsUserAgentsApart(t *testing.T) {
+	cache := NewMemoryCache()
+	runs := 0
+	run := func() ([]SearchResult, *SERPMetadata, error) {
+		runs++
+		return []SearchResult{{URL: "https://example.com/"}}, &SERPMetadata{}, nil
+	}
+	ctx := context.Background()
+	for _, userAgent := range []string{"Mozilla/5.0 (X11; Linux x86_64)", "Mozilla/5.0 (iPhone)"} {
+		opts := SearchOptions{Cache: cache, UserAgent: userAgent}
+		cachedSearch(ctx, "golang", opts, run)
+		cachedSearch(ctx, "golang", opts, run)
+	}
+	if runs != 2 {
+		t.Errorf("search ran %d times for two user agents, want one entry each", runs)
+	}
+}
+
+func TestCachedSearchSkipsCustomExtractor(t *testing.T) {
+	cache := NewMemoryCache()
+	opts := SearchOptions{
+		Cache: cache,
+		Extractor: ExtractorFunc(func(*html.Node) ([]SearchResult, SERPFeatures, error) {
+			return nil, SERPFeatures{}, nil
+		}),
+	}
+	runs := 0
+	run := func() ([]SearchResult, *SERPMetadata, error) {
+		runs++
+		return []SearchResult{{URL: "https://example.com/"}}, &SERPMetadata{}, nil
+	}
+	ctx := context.Background()
+	cachedSearch(ctx, "golang", opts, run)
+	cachedSearch(ctx, "golang", opts, run)
+	if runs != 2 {
+		t.Errorf("search ran %d times, want 2: a custom Extractor must bypass the cache", runs)
+	}
+}
+
+func TestCachedSearchReportsAge(t *testing.T) {
+	opts := SearchOptions{Cache: NewMemoryCache()}
+	run := func() ([]SearchResult, *SERPMetadata, error) {
+		return []SearchResult{{URL: "https://example.com/"}}, &SERPMetadata{}, nil
+	}
+	ctx := context.Background()
+	results, metadata, _ := cachedSearch(ctx, "golang", opts, run)
+	if !results[0].CachedAt.IsZero() || metadata.Age() != 0 {
+		t.Fatal("fresh results report a cache age")
+	}
+	results, metadata, _ = cachedSearch(ctx, "golang", opts, run)
+	if results[0].CachedAt.IsZero() || metadata.CachedAt.IsZero() {
+		t.Fatal("cached results do not report when they were fetched")
+	}
+	if !results[0].CachedAt.Equal(metadata.CachedAt) {
+		t.Errorf("result CachedAt %v differs from metadata CachedAt %v", results[0].CachedAt, metadata.CachedAt)
+	}
+}
@@ -0,0 +1,68 @@
+package googlesearch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SafeSearch selects Google's explicit content filter.
+type SafeSearch int
+
+const (
+	// SafeSearchDefault defers to the legacy Safe string, which itself
+	// defaults to strict filtering.
+	SafeSearchDefault SafeSearch = iota
+	SafeSearchOff
+	SafeSearchModerate
+	SafeSearchStrict
+)
+
+func (s SafeSearch) String() string {
+	switch s {
+	case SafeSearchOff:
+		return "off"
+	case SafeSearchModerate:
+		return "moderate"
+	case SafeSearchStrict:
+		return "strict"
+	}
+	return "default"
+}
+
+// ParseSafeSearch maps the values accepted by Google's different endpoints
+// ("active", "images", "off", "strict", "moderate", "medium", "on") to a
+// SafeSearch. The empty string means strict, the package default.
+func ParseSafeSearch(s string) (SafeSearch, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "active", "strict", "on", "high":
+		return SafeSearchStrict, nil
+	case "images", "moderate", "medium":
+		return SafeSearchModerate, nil
+	case "off", "none":
+		return SafeSearchOff, nil
+	}
+	return SafeSearchDefault, fmt.Errorf("google: unknown safe search setting %q", s)
+}
+
+// param returns the safe= value for the vertical selected by tbm. Web
+// search understands active/images/off while the image and video
+// verticals use strict/moderate/off.
+func (s SafeSearch) param(tbm string) string {
+	switch tbm {
+	case "isch", "vid":
+		switch s {
+		case SafeSearchOff:
+			return "off"
+		case SafeSearchModerate:
+			return "moderate"
+		}
+		return "strict"
+	}
+	switch s {
+	case SafeSearchOff:
+		return "off"
+	case SafeSearchModerate:
+		return "images"
+	}
+	return "active"
+}
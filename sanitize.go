@@ -0,0 +1,85 @@
+package googlesearch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxQueryLength is the longest query, in characters, Google accepts.
+const MaxQueryLength = 2048
+
+var (
+	ErrEmptyQuery   = errors.New("google: empty query")
+	ErrQueryTooLong = fmt.Errorf("google: query longer than %d characters", MaxQueryLength)
+)
+
+var quoteReplacer = strings.NewReplacer(
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`, "＂", `"`,
+)
+
+// SanitizeQuery cleans q the way every search does before sending it:
+// invalid UTF-8, control and invisible formatting characters are dropped,
+// typographic quotes become plain ones, whitespace (including ideographic
+// spaces) collapses to single spaces and an unbalanced trailing quote is
+// removed so it does not swallow the rest of the query into a phrase.
+// Emoji, CJK text and operators are kept as they are. It fails with
+// ErrEmptyQuery or ErrQueryTooLong.
+func SanitizeQuery(q string) (string, error) {
+	q = strings.ToValidUTF8(q, "")
+	q = quoteReplacer.Replace(q)
+	q = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r):
+			return ' '
+		case r == '\u200c' || r == '\u200d':
+			// Zero width (non-)joiners are part of emoji and some scripts.
+			return r
+		case unicode.IsControl(r) || unicode.Is(unicode.Cf, r):
+			return -1
+		}
+		return r
+	}, q)
+	q = strings.Join(strings.Fields(q), " ")
+
+	if strings.Count(q, `"`)%2 == 1 {
+		i := strings.LastIndex(q, `"`)
+		q = strings.Join(strings.Fields(q[:i]+" "+q[i+1:]), " ")
+	}
+
+	if q == "" {
+		return "", ErrEmptyQuery
+	}
+	if utf8.RuneCountInString(q) > MaxQueryLength {
+		return "", ErrQueryTooLong
+	}
+	return q, nil
+}
+
+// searchOperators are the prefixes Google reads as operators.
+var searchOperators = []string{
+	"site:", "filetype:", "ext:", "intitle:", "allintitle:", "inurl:", "allinurl:",
+	"intext:", "allintext:", "inanchor:", "before:", "after:", "related:", "cache:",
+	"define:", "source:", "location:",
+}
+
+// isOperatorWord reports whether Google would treat word as an operator
+// rather than a search term.
+func isOperatorWord(word string) bool {
+	switch word {
+	case "OR", "AND", "|":
+		return true
+	}
+	if strings.HasPrefix(word, "-") || strings.HasPrefix(word, "~") {
+		return true
+	}
+	lower := strings.ToLower(word)
+	for _, op := range searchOperators {
+		if strings.HasPrefix(lower, op) {
+			return true
+		}
+	}
+	return strings.HasPrefix(word, "AROUND(")
+}
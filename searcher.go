@@ -0,0 +1,371 @@
+package googlesearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Searcher runs searches with a fixed set of options and keeps its session
+// (cookies, user agent, warm-up state) across queries.
+//
+// A Searcher is safe for concurrent use by multiple goroutines. They share
+// one HTTP transport and cookie jar, the pacing limits, the proxy provider
+// and any BlockRegistry cache, so a server should create a single Searcher
+// and use it for all requests.
+type Searcher struct {
+	opts SearchOptions
+	sess *session
+
+	// inflight coalesces identical concurrent queries into one fetch.
+	inflight singleflight.Group
+
+	// profiles is shared by a Searcher and all profiles derived from it.
+	profiles *profileSet
+}
+
+// ErrClosed is returned by the methods of a Searcher or monitor that has
+// been closed.
+var ErrClosed = errors.New("google: closed")
+
+type profileSet struct {
+	mu       sync.Mutex
+	searcher map[string]*Searcher
+	closed   bool
+
+	// running counts the calls in progress on any profile; abort cancels
+	// them once Close gives up waiting.
+	running  sync.WaitGroup
+	aborted  context.Context
+	abort    context.CancelFunc
+	closeErr error
+}
+
+func NewSearcher(opts SearchOptions) (*Searcher, error) {
+	opts, err := prepareOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	s := &Searcher{opts: opts, sess: newSession(opts)}
+	s.profiles = &profileSet{searcher: map[string]*Searcher{"": s}}
+	s.profiles.aborted, s.profiles.abort = context.WithCancel(context.Background())
+	if err := s.loadSessionFile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Profile returns the Searcher for the logical profile name, creating it
+// on first use. Each profile keeps its own cookies, user agent and warm-up
+// state, so personalization picked up by one never leaks into another;
+// set DisablePersonalization as well to keep runs comparable over time.
+// Profiles share the options, pacing limits and proxies of s, and cached
+// results are kept apart per profile. The empty name is the Searcher
+// returned by NewSearcher.
+func (s *Searcher) Profile(name string) *Searcher {
+	s.profiles.mu.Lock()
+	defer s.profiles.mu.Unlock()
+	p, ok := s.profiles.searcher[name]
+	if !ok {
+		opts := s.opts
+		opts.profile = name
+		p = &Searcher{opts: opts, sess: newSessionWithPacer(opts, s.sess.pace), profiles: s.profiles}
+		// A profile whose session file cannot be restored starts fresh.
+		p.loadSessionFile()
+		s.profiles.searcher[name] = p
+	}
+	return p
+}
+
+// QueryOverrides are the settings that can differ between searches on one
+// Searcher, see With. Zero fields keep the Searcher's value.
+type QueryOverrides struct {
+	NumResults int
+	StartNum   int
+	Lang       Language
+	Region     Region
+	// Location replaces both Location and Coordinates of the Searcher.
+	Location    string
+	SafeSearch  SafeSearch
+	TimeRange   TimeRange
+	Unique      bool
+	ExtraParams url.Values
+	// UserAgent is sent instead of the Searcher's, e.g. to search as a
+	// mobile device.
+	UserAgent string
+}
+
+// With returns a Searcher that runs its searches with o applied to the
+// options of s. It shares the session, pacing limits and lifetime of s, so
+// a server can serve differently configured requests as a single visitor
+// under one rate limit. Cached results are kept apart by their options.
+func (s *Searcher) With(o QueryOverrides) (*Searcher, error) {
+	opts := s.opts
+	if o.NumResults > 0 {
+		opts.NumResults = o.NumResults
+	}
+	if o.StartNum > 0 {
+		opts.StartNum = o.StartNum
+	}
+	if o.Lang != "" {
+		lang, err := ParseLanguage(string(o.Lang))
+		if err != nil {
+			return nil, err
+		}
+		opts.Lang = lang
+	}
+	if o.Region != "" {
+		region, err := ParseRegion(string(o.Region))
+		if err != nil {
+			return nil, err
+		}
+		opts.Region = region
+	}
+	if o.Location != "" {
+		uule, err := resolveUULE(o.Location, nil)
+		if err != nil {
+			return nil, err
+		}
+		opts.Location, opts.Coordinates = uule, nil
+	}
+	if o.SafeSearch != SafeSearchDefault {
+		opts.SafeSearch = o.SafeSearch
+	}
+	if o.TimeRange != TimeRangeAny {
+		timeRange, err := ParseTimeRange(string(o.TimeRange))
+		if err != nil {
+			return nil, err
+		}
+		opts.TimeRange = timeRange
+	}
+	if o.Unique {
+		opts.Unique = true
+	}
+	if o.ExtraParams != nil {
+		opts.ExtraParams = o.ExtraParams
+	}
+	if o.UserAgent != "" {
+		opts.UserAgent = o.UserAgent
+	}
+	return &Searcher{opts: opts, sess: s.sess.withOptions(opts), profiles: s.profiles}, nil
+}
+
+// BytesDownloaded returns the compressed response bytes the Searcher has
+// received so far. Profiles count separately.
+func (s *Searcher) BytesDownloaded() int64 {
+	return s.sess.downloaded.Load()
+}
+
+// Reset drops the cookies, user agents and warm-up state of this
+// Searcher's session, starting it over as a fresh visitor.
+func (s *Searcher) Reset() {
+	s.sess.reset()
+}
+
+// Search returns up to opts.NumResults results for term.
+func (s *Searcher) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	results, _, err := s.SearchWithMetadata(ctx, term)
+	return results, err
+}
+
+// SearchWithMetadata is like Search but also returns the answer boxes and
+// other SERP metadata. Concurrent calls for the same query share a single
+// fetch unless DisableCoalescing is set; the first caller's context governs
+// that fetch.
+func (s *Searcher) SearchWithMetadata(ctx context.Context, term string) ([]SearchResult, *SERPMetadata, error) {
+	ctx, done, err := s.begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer done()
+
+	if s.opts.DisableCoalescing {
+		return s.search(ctx, term)
+	}
+
+	ch := s.inflight.DoChan(coalesceKey(term), func() (interface{}, error) {
+		results, metadata, err := s.search(ctx, term)
+		return searchOutcome{results, metadata}, err
+	})
+	select {
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case res := <-ch:
+		outcome, _ := res.Val.(searchOutcome)
+		if !res.Shared {
+			return outcome.results, outcome.metadata, res.Err
+		}
+		// Hand every caller its own copies so they can modify them freely.
+		results := append([]SearchResult(nil), outcome.results...)
+		var metadata *SERPMetadata
+		if outcome.metadata != nil {
+			m := *outcome.metadata
+			metadata = &m
+		}
+		return results, metadata, res.Err
+	}
+}
+
+// SearchStream is like the package level SearchStream but runs on the
+// Searcher's session. Results are streamed as each page is parsed and are
+// neither coalesced nor cached.
+func (s *Searcher) SearchStream(ctx context.Context, term string) <-chan SearchResponse {
+	ctx, done, err := s.begin(ctx)
+	labels := searchLabels(ctx, s.opts)
+	if err != nil {
+		ch := make(chan SearchResponse, 1)
+		ch <- SearchResponse{Err: err, Labels: labels}
+		close(ch)
+		return ch
+	}
+	return streamResponses(ctx, labels, done, func(deliver func(SearchResponse) error) error {
+		_, err := walkSession(ctx, s.sess, term, deliver)
+		return err
+	})
+}
+
+type searchOutcome struct {
+	results  []SearchResult
+	metadata *SERPMetadata
+}
+
+func (s *Searcher) search(ctx context.Context, term string) ([]SearchResult, *SERPMetadata, error) {
+	results, metadata, err := cachedSearch(ctx, term, s.opts, func() ([]SearchResult, *SERPMetadata, error) {
+		var results []SearchResult
+		metadata, err := walkSession(ctx, s.sess, term, func(resp SearchResponse) error {
+			results = append(results, resp.Result)
+			return nil
+		})
+		return results, metadata, err
+	})
+	return reRank(results, s.opts), metadata, err
+}
+
+// coalesceKey normalizes term so that queries differing only in case or
+// spacing are coalesced.
+func coalesceKey(term string) string {
+	return strings.ToLower(strings.Join(strings.Fields(term), " "))
+}
+
+// WarmUp performs the session warm-up right away instead of before the
+// first search. It is a no-op unless opts.WarmUp is set.
+func (s *Searcher) WarmUp(ctx context.Context) error {
+	ctx, done, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return s.sess.warmUp(ctx)
+}
+
+// begin registers a call with the Searcher so Close waits for it. The
+// returned context is also cancelled when Close runs out of time.
+func (s *Searcher) begin(ctx context.Context) (context.Context, func(), error) {
+	p := s.profiles
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ctx, nil, ErrClosed
+	}
+	p.running.Add(1)
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(p.aborted, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+		p.running.Done()
+	}, nil
+}
+
+// Close shuts the Searcher and all its profiles down: new calls fail with
+// ErrClosed, the searches in progress are allowed to finish, which
+// completes their cache and journal writes, and every session is saved to
+// SessionFile if one is set. If ctx ends first, the remaining searches are
+// cancelled and waited for before the sessions are saved, and Close
+// returns ctx's error. Records of AuditLog still being written to its
+// Store are waited for until ctx ends. Closing again returns the result of
+// the first Close. A Searcher buffers no metrics; BytesDownloaded and the
+// statistics of a ProxyPool stay readable after Close.
+func (s *Searcher) Close(ctx context.Context) error {
+	p := s.profiles
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		p.running.Wait()
+		return p.closeErr
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		p.running.Wait()
+		close(drained)
+	}()
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+		p.abort()
+		<-drained
+	}
+	p.abort()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, profile := range p.searcher {
+		if serr := profile.saveSessionFile(); serr != nil {
+			err = errors.Join(err, serr)
+		}
+	}
+	if audit := s.opts.AuditLog; audit != nil {
+		if ferr := audit.Flush(ctx); ferr != nil && !errors.Is(err, ferr) {
+			err = errors.Join(err, ferr)
+		}
+	}
+	p.closeErr = err
+	return err
+}
+
+// sessionFile returns the SessionFile of this profile.
+func (s *Searcher) sessionFile() string {
+	if s.opts.SessionFile == "" || s.opts.profile == "" {
+		return s.opts.SessionFile
+	}
+	return s.opts.SessionFile + "." + url.PathEscape(s.opts.profile)
+}
+
+func (s *Searcher) loadSessionFile() error {
+	path := s.sessionFile()
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err := s.LoadSession(path); err != nil {
+		return fmt.Errorf("google: restoring session: %w", err)
+	}
+	return nil
+}
+
+func (s *Searcher) saveSessionFile() error {
+	path := s.sessionFile()
+	if path == "" {
+		return nil
+	}
+	if err := s.SaveSession(path); err != nil {
+		return fmt.Errorf("google: saving session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package googlesearch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeGoogle is a TLS server answering every search with a page from
+// testdata/serp. Requests reach it through fakeProxy, which tunnels the
+// CONNECT requests of the client, so the Searcher talks to it exactly as
+// it would to Google through a proxy.
+type fakeGoogle struct {
+	*httptest.Server
+	searches atomic.Int64
+}
+
+func newFakeGoogle(t *testing.T, page string) *fakeGoogle {
+	t.Helper()
+	return newFakeGoogleFunc(t, servePage(t, page))
+}
+
+// newFakeGoogleFunc is newFakeGoogle with a handler of its own.
+func newFakeGoogleFunc(t *testing.T, handler http.HandlerFunc) *fakeGoogle {
+	t.Helper()
+	g := &fakeGoogle{}
+	g.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search" {
+			g.searches.Add(1)
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(g.Close)
+	return g
+}
+
+// servePage returns a handler writing the named page of testdata/serp.
+func servePage(t *testing.T, page string) http.HandlerFunc {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "serp", page+".html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(body)
+	}
+}
+
+// fakeProxy is an HTTP proxy that tunnels every CONNECT request to
+// backend, whatever host was asked for.
+type fakeProxy struct {
+	*httptest.Server
+	tunnels atomic.Int64
+}
+
+func newFakeProxy(t *testing.T, backend *fakeGoogle) *fakeProxy {
+	t.Helper()
+	p := &fakeProxy{}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "CONNECT only", http.StatusMethodNotAllowed)
+			return
+		}
+		p.tunnels.Add(1)
+		upstream, err := net.Dial("tcp", backend.Listener.Addr().String())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go func() {
+			io.Copy(upstream, buf)
+			upstream.Close()
+		}()
+		io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+// searchConcurrently runs n searches on s at once, each for its own query,
+// and fails t if any of them fails or finds nothing.
+func searchConcurrently(t *testing.T, s *Searcher, n int) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results, err := s.Search(ctx, fmt.Sprintf("golang tutorial %d", i))
+			if err == nil && len(results) == 0 {
+				err = ErrNoResults
+			}
+			if err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestSearcherConcurrentSearch(t *testing.T) {
+	google := newFakeGoogle(t, "lite")
+	proxy := newFakeProxy(t, google)
+	s, err := NewSearcher(SearchOptions{
+		NumResults:         3,
+		Proxy:              proxy.URL,
+		InsecureSkipVerify: true,
+		DisableCoalescing:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const searches = 16
+	searchConcurrently(t, s, searches)
+	if got := google.searches.Load(); got < searches {
+		t.Errorf("google saw %d searches, want at least %d", got, searches)
+	}
+	if s.BytesDownloaded() == 0 {
+		t.Error("BytesDownloaded is 0 after searching")
+	}
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProxyPoolConcurrent(t *testing.T) {
+	google := newFakeGoogle(t, "lite")
+	var proxies []*fakeProxy
+	var urls []string
+	for i := 0; i < 3; i++ {
+		proxy := newFakeProxy(t, google)
+		proxies = append(proxies, proxy)
+		urls = append(urls, proxy.URL)
+	}
+	pool, err := NewProxyPool(urls...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSearcher(SearchOptions{
+		NumResults:         3,
+		ProxyProvider:      pool,
+		InsecureSkipVerify: true,
+		DisableCoalescing:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the statistics while the searches update them.
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				pool.Stats()
+			}
+		}
+	}()
+	searchConcurrently(t, s, 24)
+	close(stop)
+	readers.Wait()
+
+	var requests, successes int
+	for _, stats := range pool.Stats() {
+		if stats.Requests == 0 {
+			t.Errorf("%s was never used", stats.Proxy)
+		}
+		requests += stats.Requests
+		successes += stats.Successes
+	}
+	if want := int(google.searches.Load()); requests != want || successes != want {
+		t.Errorf("pool counted %d requests and %d successes, google saw %d searches", requests, successes, want)
+	}
+	for _, proxy := range proxies {
+		if proxy.tunnels.Load() == 0 {
+			t.Errorf("proxy %s tunneled nothing", proxy.URL)
+		}
+	}
+}
+
+func TestBlockRegistryConcurrent(t *testing.T) {
+	google := newFakeGoogle(t, "lite")
+	captcha := newFakeGoogle(t, "captcha")
+	good := newFakeProxy(t, google)
+	blocked := newFakeProxy(t, captcha)
+	pool, err := NewProxyPool(good.URL, blocked.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewBlockRegistry(nil, time.Hour)
+	s, err := NewSearcher(SearchOptions{
+		NumResults:         3,
+		ProxyProvider:      pool,
+		BlockRegistry:      registry,
+		InsecureSkipVerify: true,
+		DisableCoalescing:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Searches through the blocked proxy fail until the registry
+			// takes it out of rotation; only the outcome under load
+			// matters here.
+			s.Search(ctx, fmt.Sprintf("golang tutorial %d", i))
+		}()
+	}
+	// Exercise the registry directly alongside the searches.
+	other, _ := url.Parse("http://other.example:8080")
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			registry.MarkBlocked(ctx, other)
+			registry.BlockedUntil(ctx, other)
+			registry.Clear(ctx, other)
+		}()
+	}
+	wg.Wait()
+
+	blockedURL, _ := url.Parse(blocked.URL)
+	until, err := registry.BlockedUntil(ctx, blockedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if until.IsZero() {
+		t.Error("the proxy serving captchas is not cooling down")
+	}
+	goodURL, _ := url.Parse(good.URL)
+	if until, _ := registry.BlockedUntil(ctx, goodURL); !until.IsZero() {
+		t.Errorf("the working proxy is cooling down until %v", until)
+	}
+
+	// With the blocked proxy out of rotation every search succeeds.
+	searchConcurrently(t, s, 8)
+	if got := captcha.searches.Load(); got == 0 {
+		t.Error("the captcha backend was never reached")
+	}
+}
+
+func TestSearcherWithSharesSession(t *testing.T) {
+	google := newFakeGoogle(t, "lite")
+	proxy := newFakeProxy(t, google)
+	s, err := NewSearcher(SearchOptions{NumResults: 3, Proxy: proxy.URL, InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.With(QueryOverrides{Lang: "klingon"}); err == nil {
+		t.Error("With accepted an unsupported language")
+	}
+	german, err := s.With(QueryOverrides{Lang: "de", Region: "uk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if german.opts.Region != "gb" || s.opts.Lang != "" {
+		t.Errorf("With set region %q and left the Searcher's language %q", german.opts.Region, s.opts.Lang)
+	}
+	if _, err := german.Search(context.Background(), "golang"); err != nil {
+		t.Fatal(err)
+	}
+	if s.BytesDownloaded() == 0 {
+		t.Error("the search of With did not run on the Searcher's session")
+	}
+}
@@ -0,0 +1,158 @@
+package googlesearch
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/andybalholm/cascadia"
+)
+
+// SelectorBundle holds the CSS selectors the organic result parser relies
+// on. When Google changes its markup, a newer bundle can be installed with
+// SetSelectors or fetched with UpdateSelectors instead of redeploying.
+type SelectorBundle struct {
+	Version int `json:"version"`
+	// Result matches one organic result block; Link, Title and Description
+	// are looked up inside it, Title inside the link.
+	Result      string `json:"result"`
+	Link        string `json:"link"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// DefaultSelectors is the bundle compiled into this version.
+var DefaultSelectors = SelectorBundle{
+	Version:     1,
+	Result:      "div.ezO2md",
+	Link:        "a[href]",
+	Title:       "span.CVA68e",
+	Description: "span.FrIlee",
+}
+
+// DefaultSelectorsURL is the bundle published in the repository.
+const DefaultSelectorsURL = "https://raw.githubusercontent.com/1hehaq/googlesearch/main/selectors.json"
+
+var activeSelectors atomic.Pointer[SelectorBundle]
+
+// CurrentSelectors returns the bundle the parser uses.
+func CurrentSelectors() SelectorBundle {
+	if b := activeSelectors.Load(); b != nil {
+		return *b
+	}
+	return DefaultSelectors
+}
+
+// SetSelectors validates b and makes the parser use it from now on.
+func SetSelectors(b SelectorBundle) error {
+	if err := b.validate(); err != nil {
+		return err
+	}
+	activeSelectors.Store(&b)
+	return nil
+}
+
+func (b SelectorBundle) validate() error {
+	for name, sel := range map[string]string{
+		"result": b.Result, "link": b.Link, "title": b.Title, "description": b.Description,
+	} {
+		if sel == "" {
+			return fmt.Errorf("google: selector bundle: missing %s selector", name)
+		}
+		if _, err := cascadia.Compile(sel); err != nil {
+			return fmt.Errorf("google: selector bundle: %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// SelectorManifest is the published form of a bundle. SHA256 is the hex
+// digest of the exact Bundle bytes and Signature, if present, their
+// base64 Ed25519 signature.
+type SelectorManifest struct {
+	Bundle    json.RawMessage `json:"bundle"`
+	SHA256    string          `json:"sha256"`
+	Signature string          `json:"signature,omitempty"`
+}
+
+// SelectorUpdateOptions configure UpdateSelectors.
+type SelectorUpdateOptions struct {
+	// URL of the manifest; it defaults to DefaultSelectorsURL.
+	URL string
+	// PublicKey, if set, requires a valid signature by its private key.
+	PublicKey ed25519.PublicKey
+	Client    *http.Client
+}
+
+// UpdateSelectors fetches a SelectorManifest, verifies its checksum and
+// signature and installs the bundle if its Version is newer than the
+// current one. It returns the bundle in use afterwards. Call it once at
+// startup; on any error the current bundle stays in place.
+func UpdateSelectors(ctx context.Context, opts SelectorUpdateOptions) (SelectorBundle, error) {
+	current := CurrentSelectors()
+	if opts.URL == "" {
+		opts.URL = DefaultSelectorsURL
+	}
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", opts.URL, nil)
+	if err != nil {
+		return current, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return current, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return current, fmt.Errorf("google: selector update: %s returned %s", opts.URL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return current, err
+	}
+
+	bundle, err := verifySelectorManifest(data, opts.PublicKey)
+	if err != nil {
+		return current, err
+	}
+	if bundle.Version <= current.Version {
+		return current, nil
+	}
+	if err := SetSelectors(bundle); err != nil {
+		return current, err
+	}
+	return bundle, nil
+}
+
+func verifySelectorManifest(data []byte, key ed25519.PublicKey) (SelectorBundle, error) {
+	var manifest SelectorManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return SelectorBundle{}, fmt.Errorf("google: selector update: %w", err)
+	}
+	sum := sha256.Sum256(manifest.Bundle)
+	if hex.EncodeToString(sum[:]) != manifest.SHA256 {
+		return SelectorBundle{}, fmt.Errorf("google: selector update: checksum mismatch")
+	}
+	if key != nil {
+		sig, err := base64.StdEncoding.DecodeString(manifest.Signature)
+		if err != nil || !ed25519.Verify(key, manifest.Bundle, sig) {
+			return SelectorBundle{}, fmt.Errorf("google: selector update: invalid signature")
+		}
+	}
+	var bundle SelectorBundle
+	if err := json.Unmarshal(manifest.Bundle, &bundle); err != nil {
+		return SelectorBundle{}, fmt.Errorf("google: selector update: %w", err)
+	}
+	return bundle, bundle.validate()
+}
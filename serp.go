@@ -0,0 +1,153 @@
+package googlesearch
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// SERP is the structured outcome of a whole search, the home for
+// everything extracted beyond the organic results.
+type SERP struct {
+	Query          string
+	Locale         Locale
+	OrganicResults []SearchResult
+	Ads            []Ad
+	// Features holds the feature summary and the parsed answer boxes. Its
+	// Ads are always nil; they are in Ads.
+	Features   SERPMetadata
+	Pagination Pagination
+	Stats      Stats
+}
+
+type Pagination struct {
+	StartNum     int
+	PagesFetched int
+	// Exhausted reports that Google ran out of new results.
+	Exhausted bool
+}
+
+type Stats struct {
+	// TotalResults and SearchTime are Google's own figures, zero when the
+	// page did not show them.
+	TotalResults int64
+	SearchTime   time.Duration
+	// Elapsed is the wall-clock time the search took and BytesDownloaded
+	// the compressed size of the responses it received.
+	Elapsed         time.Duration
+	BytesDownloaded int64
+	// CacheAge is the age of results served from the cache, zero for
+	// fresh ones.
+	CacheAge time.Duration
+}
+
+// SearchSERP runs a search like SearchWithMetadata and returns everything
+// as a SERP. On error the SERP holds whatever was collected before it.
+func SearchSERP(ctx context.Context, query string, opts SearchOptions) (*SERP, error) {
+	opts, err := prepareOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	began := time.Now()
+	results, metadata, err := searchPrepared(ctx, query, opts)
+	return newSERP(query, opts, results, metadata, time.Since(began)), err
+}
+
+// SearchSERP is SearchSERP on the Searcher's session.
+func (s *Searcher) SearchSERP(ctx context.Context, query string) (*SERP, error) {
+	began := time.Now()
+	results, metadata, err := s.SearchWithMetadata(ctx, query)
+	return newSERP(query, s.opts, results, metadata, time.Since(began)), err
+}
+
+func newSERP(query string, opts SearchOptions, results []SearchResult, metadata *SERPMetadata, elapsed time.Duration) *SERP {
+	serp := &SERP{
+		Query:          query,
+		Locale:         Locale{Lang: opts.Lang, Region: opts.Region},
+		OrganicResults: results,
+		Pagination:     Pagination{StartNum: opts.StartNum},
+		Stats:          Stats{Elapsed: elapsed},
+	}
+	if metadata != nil {
+		serp.Features = *metadata
+		serp.Ads, serp.Features.Ads = metadata.Ads, nil
+		serp.Pagination.PagesFetched = metadata.PagesFetched
+		serp.Pagination.Exhausted = metadata.Exhausted
+		serp.Stats.BytesDownloaded = metadata.BytesDownloaded
+		serp.Stats.CacheAge = metadata.Age()
+		if metadata.ResultStats != nil {
+			serp.Stats.TotalResults = metadata.ResultStats.TotalResults
+			serp.Stats.SearchTime = metadata.ResultStats.SearchTime
+		}
+	}
+	return serp
+}
+
+// SERPPage is a single results page as returned by FetchSERP: the parsed
+// results and metadata together with the raw response they came from.
+type SERPPage struct {
+	Results  []SearchResult
+	Metadata SERPMetadata
+
+	// URL is the final request URL after redirects.
+	URL        string
+	StatusCode int
+	Header     http.Header
+	HTML       []byte
+}
+
+// FetchSERP fetches and parses the single results page of term starting at
+// result offset start. It applies the same request construction, consent
+// handling, proxy rotation and retries as Search but no pagination,
+// deduplication or caching, so callers can build their own on top.
+//
+// A page without organic results is not an error; its Results are empty.
+func FetchSERP(ctx context.Context, term string, start int, opts SearchOptions) (*SERPPage, error) {
+	opts, err := prepareOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return fetchSERP(ctx, newSession(opts), term, start)
+}
+
+// FetchSERP is FetchSERP on the Searcher's session.
+func (s *Searcher) FetchSERP(ctx context.Context, term string, start int) (*SERPPage, error) {
+	ctx, done, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return fetchSERP(ctx, s.sess, term, start)
+}
+
+func fetchSERP(ctx context.Context, sess *session, term string, start int) (*SERPPage, error) {
+	term, err := SanitizeQuery(term)
+	if err != nil {
+		return nil, err
+	}
+	if err := sess.prepare(ctx); err != nil {
+		return nil, err
+	}
+	var downloaded atomic.Int64
+	page, err := sess.fetchPage(withByteMeter(ctx, &downloaded), term, start)
+	if err != nil {
+		return nil, err
+	}
+
+	serp := &SERPPage{
+		Results:  page.results,
+		Metadata: page.metadata,
+		HTML:     page.body,
+	}
+	serp.Metadata.PagesFetched = 1
+	serp.Metadata.BytesDownloaded = downloaded.Load()
+	if page.resp != nil {
+		serp.StatusCode = page.resp.StatusCode
+		serp.Header = page.resp.Header
+		if page.resp.Request != nil {
+			serp.URL = page.resp.Request.URL.String()
+		}
+	}
+	return serp, nil
+}
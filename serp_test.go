@@ -0,0 +1,17 @@
+package googlesearch
+
+import "testing"
+
+func TestNewSERPKeepsAdsOnce(t *testing.T) {
+	metadata := &SERPMetadata{Ads: []Ad{{Block: "top", Position: 1, URL: "https://ads.example.com/"}}}
+	serp := newSERP("golang", SearchOptions{}, nil, metadata, 0)
+	if len(serp.Ads) != 1 {
+		t.Errorf("SERP.Ads holds %d ads, want 1", len(serp.Ads))
+	}
+	if serp.Features.Ads != nil {
+		t.Errorf("SERP.Features repeats the ads: %+v", serp.Features.Ads)
+	}
+	if len(metadata.Ads) != 1 {
+		t.Error("newSERP dropped the ads of the metadata it was given")
+	}
+}
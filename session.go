@@ -0,0 +1,203 @@
+package googlesearch
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// session holds the state shared by consecutive page requests: the HTTP
+// client with its cookie jar and the user agents picked so far. A Searcher
+// keeps one session across queries; package level functions use a fresh
+// one per search.
+type session struct {
+	opts SearchOptions
+	pace *pacer
+	*visitor
+}
+
+// visitor is the state of a session that outlives its options, so that
+// sessions created by withOptions act as the same visitor to Google.
+type visitor struct {
+	// downloaded counts the response bytes of every request the session
+	// made, across resets.
+	downloaded atomic.Int64
+
+	// warmMu serializes warm-ups so concurrent searches on a fresh
+	// session do not all visit the homepage.
+	warmMu sync.Mutex
+
+	mu         sync.Mutex
+	client     *http.Client
+	userAgents map[string]string
+	referers   map[string]string
+	warmedUp   bool
+	// userAgentPages counts the pages requested since the last reset, for
+	// UserAgentPages.
+	userAgentPages int
+	// consentCookies are the cookies obtained by completing the consent
+	// page, sent instead of the preset ones.
+	consentCookies []*http.Cookie
+}
+
+func newSession(opts SearchOptions) *session {
+	return newSessionWithPacer(opts, newPacer(opts))
+}
+
+// newSessionWithPacer returns a session that shares pace with others, so
+// their requests count against the same limits.
+func newSessionWithPacer(opts SearchOptions, pace *pacer) *session {
+	s := &session{opts: opts, pace: pace, visitor: &visitor{}}
+	s.reset()
+	return s
+}
+
+// withOptions returns a session sharing the visitor state and pacer of s
+// that requests pages with opts. opts may only differ from the options of
+// s in the per-search settings of QueryOverrides.
+func (s *session) withOptions(opts SearchOptions) *session {
+	return &session{opts: opts, pace: s.pace, visitor: s.visitor}
+}
+
+// reset drops cookies, user agents and warm-up state so the next request
+// starts a fresh session.
+func (s *session) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = newClient(s.opts, &s.downloaded)
+	s.userAgents = make(map[string]string)
+	s.referers = make(map[string]string)
+	s.warmedUp = false
+	s.userAgentPages = 0
+	s.consentCookies = nil
+}
+
+func (s *session) httpClient() *http.Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.client
+}
+
+// userAgent returns the user agent to send for profile: opts.UserAgent for
+// the default profile if set, otherwise one kept sticky per profile unless
+// RotateUserAgent is set.
+func (s *session) userAgent(profile fetchProfile) string {
+	if s.opts.UserAgent != "" && profile.name == defaultProfile.name {
+		return s.opts.UserAgent
+	}
+	if s.opts.RotateUserAgent {
+		return profile.userAgent()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ua, ok := s.userAgents[profile.name]
+	if !ok {
+		ua = profile.userAgent()
+		s.userAgents[profile.name] = ua
+	}
+	return ua
+}
+
+// referer returns the last page requested with profile so follow-up pages
+// look like in-site navigation. Rotating sessions never send one.
+func (s *session) referer(profile fetchProfile) string {
+	if s.opts.RotateUserAgent {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.referers[profile.name]
+}
+
+func (s *session) setReferer(profile fetchProfile, pageURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.referers[profile.name] = pageURL
+}
+
+// prepare waits for the pacer before the next page request, rotating to a
+// fresh session when the pacing profile or UserAgentPages asks for it, and
+// performs the warm-up if it is still due.
+func (s *session) prepare(ctx context.Context) error {
+	rotate, err := s.pace.wait(ctx)
+	if err != nil {
+		return err
+	}
+	if rotate || s.userAgentUsedUp() {
+		s.reset()
+	}
+	s.mu.Lock()
+	s.userAgentPages++
+	s.mu.Unlock()
+	return s.warmUp(ctx)
+}
+
+// userAgentUsedUp reports whether the user agent has served the
+// UserAgentPages pages it may.
+func (s *session) userAgentUsedUp() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.opts.UserAgentPages > 0 && s.userAgentPages >= s.opts.UserAgentPages
+}
+
+// warmUp visits the Google homepage, and optionally runs WarmUpQuery, to
+// collect fresh cookies before the first real search of the session.
+func (s *session) warmUp(ctx context.Context) error {
+	if !s.opts.WarmUp {
+		return nil
+	}
+	s.warmMu.Lock()
+	defer s.warmMu.Unlock()
+
+	s.mu.Lock()
+	done := s.warmedUp
+	s.mu.Unlock()
+	if done || !s.opts.WarmUp {
+		return nil
+	}
+
+	proxy, release, err := s.nextProxy(ctx)
+	if err != nil {
+		return err
+	}
+	ctx = withProxy(s.opts.domainContext(ctx), proxy)
+	err = s.visit(ctx, "https://www."+searchDomain(ctx)+"/")
+	if err == nil && s.opts.WarmUpQuery != "" {
+		var resp *http.Response
+		resp, err = s.sendRequest(ctx, s.opts.WarmUpQuery, 0, defaultProfile)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}
+	if release != nil {
+		release(err == nil)
+	}
+	if err == nil {
+		s.mu.Lock()
+		s.warmedUp = true
+		s.mu.Unlock()
+	}
+	return err
+}
+
+func (s *session) visit(ctx context.Context, pageURL string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return err
+	}
+	setBrowserHeaders(req.Header, s.userAgent(defaultProfile), string(s.opts.Lang), string(s.opts.Region), "")
+
+	resp, err := s.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(io.Discard, resp.Body)
+	if err == nil {
+		s.setReferer(defaultProfile, pageURL)
+	}
+	return err
+}
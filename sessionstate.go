@@ -0,0 +1,172 @@
+package googlesearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+const sessionStateVersion = 1
+
+type sessionState struct {
+	Version    int               `json:"version"`
+	SavedAt    time.Time         `json:"saved_at"`
+	UserAgents map[string]string `json:"user_agents"`
+	// Cookies are keyed by the URL that set them and keep their domain,
+	// path and expiry.
+	Cookies map[string][]*http.Cookie `json:"cookies,omitempty"`
+	// ConsentCookies are the cookies of a completed consent page, so a
+	// restored session does not hit it again.
+	ConsentCookies []*http.Cookie `json:"consent_cookies,omitempty"`
+	WarmedUp       bool           `json:"warmed_up"`
+	PagesServed    int            `json:"pages_in_session"`
+	LastRequest    time.Time      `json:"last_request"`
+}
+
+// SaveSession writes the cookies, chosen user agents and pacing state of
+// the Searcher to path so a later process can continue the session with
+// LoadSession.
+func (s *Searcher) SaveSession(path string) error {
+	data, err := json.MarshalIndent(s.sess.state(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// LoadSession restores a session saved with SaveSession.
+func (s *Searcher) LoadSession(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var state sessionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("google: decoding session file: %w", err)
+	}
+	if state.Version != sessionStateVersion {
+		return fmt.Errorf("google: unsupported session file version: %d", state.Version)
+	}
+	s.sess.restore(state)
+	return nil
+}
+
+func (s *session) state() sessionState {
+	s.mu.Lock()
+	state := sessionState{
+		Version:    sessionStateVersion,
+		SavedAt:    time.Now(),
+		UserAgents: make(map[string]string, len(s.userAgents)),
+		WarmedUp:   s.warmedUp,
+	}
+	for name, ua := range s.userAgents {
+		state.UserAgents[name] = ua
+	}
+	if jar, ok := s.client.Jar.(*recordingJar); ok {
+		state.Cookies = jar.cookies()
+	}
+	for _, c := range s.consentCookies {
+		state.ConsentCookies = append(state.ConsentCookies, &http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	s.mu.Unlock()
+
+	s.pace.mu.Lock()
+	state.PagesServed = s.pace.pagesInSession
+	state.LastRequest = s.pace.lastRequest
+	s.pace.mu.Unlock()
+	return state
+}
+
+func (s *session) restore(state sessionState) {
+	s.mu.Lock()
+	for name, ua := range state.UserAgents {
+		s.userAgents[name] = ua
+	}
+	s.warmedUp = state.WarmedUp
+	if len(state.ConsentCookies) > 0 {
+		s.consentCookies = state.ConsentCookies
+	}
+	if jar := s.client.Jar; jar != nil {
+		for rawURL, cookies := range state.Cookies {
+			if u, err := url.Parse(rawURL); err == nil {
+				jar.SetCookies(u, cookies)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	s.pace.mu.Lock()
+	s.pace.pagesInSession = state.PagesServed
+	s.pace.lastRequest = state.LastRequest
+	s.pace.mu.Unlock()
+}
+
+// recordingJar is a cookie jar that also keeps every cookie with the
+// attributes it was set with, so sessions can be saved: a cookiejar.Jar
+// can neither be enumerated nor returns more than names and values.
+type recordingJar struct {
+	http.CookieJar
+
+	mu  sync.Mutex
+	set map[recordedCookieID]*recordedCookie
+}
+
+type recordedCookieID struct {
+	host, domain, path, name string
+}
+
+type recordedCookie struct {
+	// origin is the URL that set the cookie, without query.
+	origin string
+	cookie http.Cookie
+}
+
+func newRecordingJar() *recordingJar {
+	jar, _ := cookiejar.New(nil)
+	return &recordingJar{CookieJar: jar, set: make(map[recordedCookieID]*recordedCookie)}
+}
+
+func (j *recordingJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.CookieJar.SetCookies(u, cookies)
+
+	origin := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+	now := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, c := range cookies {
+		id := recordedCookieID{host: u.Hostname(), domain: c.Domain, path: c.Path, name: c.Name}
+		if c.MaxAge < 0 || !c.Expires.IsZero() && !c.Expires.After(now) {
+			delete(j.set, id)
+			continue
+		}
+		rec := &recordedCookie{origin: origin, cookie: *c}
+		// Max-Age is relative to now; keep it as an absolute expiry.
+		if c.MaxAge > 0 {
+			rec.cookie.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+			rec.cookie.MaxAge = 0
+		}
+		rec.cookie.Raw, rec.cookie.RawExpires, rec.cookie.Unparsed = "", "", nil
+		j.set[id] = rec
+	}
+}
+
+// cookies returns the unexpired cookies grouped by the URL that set them.
+func (j *recordingJar) cookies() map[string][]*http.Cookie {
+	now := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	byOrigin := make(map[string][]*http.Cookie)
+	for _, rec := range j.set {
+		if !rec.cookie.Expires.IsZero() && !rec.cookie.Expires.After(now) {
+			continue
+		}
+		c := rec.cookie
+		byOrigin[rec.origin] = append(byOrigin[rec.origin], &c)
+	}
+	return byOrigin
+}
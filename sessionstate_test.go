@@ -0,0 +1,109 @@
+package googlesearch
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSessionFileKeepsCookiesOfSearchDomain(t *testing.T) {
+	lite := servePage(t, "lite")
+	var mu sync.Mutex
+	var sent []string
+	google := newFakeGoogleFunc(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if c, err := r.Cookie("NID"); err == nil {
+			sent = append(sent, c.Value)
+		}
+		mu.Unlock()
+		http.SetCookie(w, &http.Cookie{Name: "NID", Value: "visitor", Domain: "google.de", Path: "/", MaxAge: 3600})
+		lite(w, r)
+	})
+	proxy := newFakeProxy(t, google)
+	opts := SearchOptions{NumResults: 3, Domain: "google.de", Proxy: proxy.URL, InsecureSkipVerify: true}
+	path := filepath.Join(t.TempDir(), "session.json")
+	ctx := context.Background()
+
+	first, err := NewSearcher(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.Search(ctx, "golang"); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.SaveSession(path); err != nil {
+		t.Fatal(err)
+	}
+	state := first.sess.state()
+	var saved []*http.Cookie
+	for _, cookies := range state.Cookies {
+		saved = append(saved, cookies...)
+	}
+	if len(saved) != 1 || saved[0].Domain != "google.de" || saved[0].Expires.IsZero() {
+		t.Fatalf("saved cookies %+v, want NID with domain and expiry", saved)
+	}
+
+	second, err := NewSearcher(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := second.LoadSession(path); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	sent = nil
+	mu.Unlock()
+	if _, err := second.Search(ctx, "golang"); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) == 0 || sent[0] != "visitor" {
+		t.Errorf("restored session sent NID cookies %q, want the saved one", sent)
+	}
+}
+
+func TestSessionFileKeepsConsentCookies(t *testing.T) {
+	lite := servePage(t, "lite")
+	var mu sync.Mutex
+	var sent []string
+	google := newFakeGoogleFunc(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if c, err := r.Cookie("SOCS"); err == nil {
+			sent = append(sent, c.Value)
+		}
+		mu.Unlock()
+		lite(w, r)
+	})
+	proxy := newFakeProxy(t, google)
+	opts := SearchOptions{NumResults: 3, Proxy: proxy.URL, InsecureSkipVerify: true}
+	path := filepath.Join(t.TempDir(), "session.json")
+
+	first, err := NewSearcher(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// As if completeConsent had accepted the consent page.
+	first.sess.consentCookies = []*http.Cookie{{Name: "SOCS", Value: "accepted"}}
+	if err := first.SaveSession(path); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := NewSearcher(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := second.LoadSession(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Search(context.Background(), "golang"); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 1 || sent[0] != "accepted" {
+		t.Errorf("restored session sent SOCS cookies %q, want the consent page's", sent)
+	}
+}
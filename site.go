@@ -0,0 +1,67 @@
+package googlesearch
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SiteSearch searches query restricted to domain and its subdomains. The
+// domain may be given with a scheme, a leading "www." or "*." and an
+// optional path prefix, e.g. "https://www.example.com/blog".
+func SiteSearch(ctx context.Context, domain, query string, n int, opts SearchOptions) ([]SearchResult, error) {
+	host, path, err := normalizeSiteDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	term := "site:" + host + path
+	if query = strings.TrimSpace(query); query != "" {
+		term += " " + query
+	}
+	opts.NumResults = n
+
+	results, _, err := search(ctx, term, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := results[:0]
+	for _, result := range results {
+		if resultOnSite(result.URL, host, path) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered, nil
+}
+
+func normalizeSiteDomain(domain string) (string, string, error) {
+	domain = strings.TrimSpace(domain)
+	if !strings.Contains(domain, "://") {
+		domain = "http://" + domain
+	}
+	u, err := url.Parse(domain)
+	if err != nil || u.Hostname() == "" {
+		return "", "", fmt.Errorf("google: invalid site domain: %q", domain)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "*.")
+	host = strings.TrimPrefix(host, "www.")
+	return host, strings.TrimSuffix(u.Path, "/"), nil
+}
+
+// resultOnSite drops results Google occasionally returns from outside the
+// requested site.
+func resultOnSite(rawURL, host, path string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	resultHost := strings.ToLower(u.Hostname())
+	if resultHost != host && !strings.HasSuffix(resultHost, "."+host) {
+		return false
+	}
+	return path == "" || strings.HasPrefix(u.Path, path)
+}
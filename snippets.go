@@ -0,0 +1,124 @@
+package googlesearch
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// FeaturedSnippet is the answer Google quotes above the results, with the
+// page it was taken from.
+type FeaturedSnippet struct {
+	Text  string
+	Title string
+	URL   string
+}
+
+func extractFeaturedSnippet(doc *goquery.Document) *FeaturedSnippet {
+	block := doc.Find("div.xpdopen, block-component, div.V3FYCf").FilterFunction(func(i int, s *goquery.Selection) bool {
+		return s.Is("div.V3FYCf") || s.Find("div.c2xzTb").Length() > 0
+	}).First()
+	if block.Length() == 0 {
+		return nil
+	}
+	snippet := &FeaturedSnippet{
+		Text: normalizeSpace(block.Find("span.hgKElc, div.LGOjhe, div.di3YZe").First().Text()),
+	}
+	block.Find("a[href]").EachWithBreak(func(i int, a *goquery.Selection) bool {
+		target := resolveGoogleURL(doc.Url, a.AttrOr("href", ""))
+		if target == "" || isGoogleURL(target) {
+			return true
+		}
+		snippet.URL = target
+		snippet.Title = normalizeSpace(a.Find("h3").First().Text())
+		return false
+	})
+	if snippet.URL == "" && snippet.Text == "" {
+		return nil
+	}
+	return snippet
+}
+
+// isGoogleURL reports whether target points back to Google itself, like
+// the "About featured snippets" link.
+func isGoogleURL(target string) bool {
+	domain := registrableDomain(target)
+	return strings.HasPrefix(domain, "google.")
+}
+
+// SnippetOwner is the featured snippet outcome of one question.
+type SnippetOwner struct {
+	Question string
+	// URL and Domain are empty when Google showed no featured snippet.
+	URL    string
+	Domain string
+	Text   string
+	Err    error
+}
+
+// DomainAnswers counts the questions whose featured snippet a domain owns.
+type DomainAnswers struct {
+	Domain    string
+	Count     int
+	Questions []string
+}
+
+// SnippetOwnership is the result of AnswerOwners.
+type SnippetOwnership struct {
+	Questions []SnippetOwner
+	// Domains are ordered by the number of answers owned.
+	Domains []DomainAnswers
+}
+
+// AnswerOwners searches every question one after the other on a single
+// Searcher and reports which page, and which registrable domain, supplies
+// the featured snippet of each, as is common in content strategy work.
+// Per-question failures are reported in SnippetOwner.Err; the returned
+// error is only set for invalid options or when ctx ends.
+func AnswerOwners(ctx context.Context, questions []string, opts SearchOptions) (*SnippetOwnership, error) {
+	if opts.NumResults == 0 {
+		opts.NumResults = 10
+	}
+	opts.MaxPages = 1
+	searcher, err := NewSearcher(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ownership := &SnippetOwnership{}
+	index := make(map[string]int)
+	for _, question := range questions {
+		if err := ctx.Err(); err != nil {
+			return ownership, err
+		}
+		owner := SnippetOwner{Question: question}
+		_, metadata, err := searcher.SearchWithMetadata(ctx, question)
+		switch {
+		case err != nil:
+			owner.Err = err
+		case metadata != nil && metadata.FeaturedSnippet != nil:
+			owner.URL = metadata.FeaturedSnippet.URL
+			owner.Text = metadata.FeaturedSnippet.Text
+			owner.Domain = registrableDomain(owner.URL)
+		}
+		ownership.Questions = append(ownership.Questions, owner)
+
+		if owner.Domain == "" {
+			continue
+		}
+		i, ok := index[owner.Domain]
+		if !ok {
+			i = len(ownership.Domains)
+			index[owner.Domain] = i
+			ownership.Domains = append(ownership.Domains, DomainAnswers{Domain: owner.Domain})
+		}
+		ownership.Domains[i].Count++
+		ownership.Domains[i].Questions = append(ownership.Domains[i].Questions, question)
+	}
+	sort.SliceStable(ownership.Domains, func(a, b int) bool {
+		return ownership.Domains[a].Count > ownership.Domains[b].Count
+	})
+	return ownership, nil
+}
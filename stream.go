@@ -0,0 +1,79 @@
+package googlesearch
+
+import (
+	"context"
+	"errors"
+)
+
+// SearchResponse is a single item delivered by SearchStream. Either Result
+// or Err is set; an error is always the last item before the channel is
+// closed.
+type SearchResponse struct {
+	Result SearchResult
+	// Page is the 1-based results page the result was found on, counted
+	// from StartNum. IndexOnPage is its 1-based position among the organic
+	// results of that page and Rank its overall position. They describe the
+	// SERP placement and do not depend on the order results are emitted in.
+	// For results with FromExpansion set, IndexOnPage is the position on the
+	// expansion page and Rank is zero.
+	Page        int
+	IndexOnPage int
+	Rank        int
+	Err         error
+	// Labels are those of the search, see WithLabels.
+	Labels Labels
+}
+
+// SearchStream runs the search in the background and streams results as
+// each page is parsed. The channel is closed when the search ends; cancel
+// ctx to stop early.
+func SearchStream(ctx context.Context, term string, opts SearchOptions) <-chan SearchResponse {
+	return streamResponses(ctx, searchLabels(ctx, opts), nil, func(deliver func(SearchResponse) error) error {
+		_, err := walk(ctx, term, opts, deliver)
+		return err
+	})
+}
+
+// streamResponses calls run in the background and sends what it delivers
+// on the returned channel, followed by its error unless ctx is done. done,
+// if not nil, is called after that, before the channel is closed.
+func streamResponses(ctx context.Context, labels Labels, done func(), run func(deliver func(SearchResponse) error) error) <-chan SearchResponse {
+	ch := make(chan SearchResponse)
+	go func() {
+		defer close(ch)
+		if done != nil {
+			defer done()
+		}
+		err := run(func(resp SearchResponse) error {
+			select {
+			case ch <- resp:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			ch <- SearchResponse{Err: err, Labels: labels}
+		}
+	}()
+	return ch
+}
+
+// ErrStopSearch can be returned by a SearchEach callback to stop the search
+// without SearchEach reporting an error.
+var ErrStopSearch = errors.New("google: search stopped by callback")
+
+// SearchEach calls fn for up to n results of query in SERP order. It stops
+// at the first error returned by fn and returns it, except for
+// ErrStopSearch. Unlike SearchStream there is no channel to drain, so an
+// early return cannot leak the fetching goroutine.
+func SearchEach(ctx context.Context, query string, n int, opts SearchOptions, fn func(SearchResult) error) error {
+	opts.NumResults = n
+	_, err := walk(ctx, query, opts, func(resp SearchResponse) error {
+		return fn(resp.Result)
+	})
+	if errors.Is(err, ErrStopSearch) {
+		return nil
+	}
+	return err
+}
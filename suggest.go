@@ -0,0 +1,75 @@
+package googlesearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Suggest returns Google's autocomplete suggestions for query, honouring
+// the Lang, Region, proxy and TLS settings of opts.
+func Suggest(ctx context.Context, query string, opts SearchOptions) ([]string, error) {
+	opts, err := prepareOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	client := newClient(opts, nil)
+
+	q := url.Values{}
+	q.Set("client", "firefox")
+	q.Set("q", query)
+	q.Set("ie", "utf-8")
+	q.Set("oe", "utf-8")
+	if opts.Lang != "" {
+		q.Set("hl", string(opts.Lang))
+	}
+	if opts.Region != "" {
+		q.Set("gl", string(opts.Region))
+	}
+
+	proxy, release := opts.ProxyProvider.Next(ctx)
+	req, err := http.NewRequestWithContext(withProxy(ctx, proxy), "GET", "https://suggestqueries.google.com/complete/search?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", getRandomUserAgent())
+
+	suggestions, err := fetchSuggestions(client, req)
+	if release != nil {
+		release(err == nil)
+	}
+	return suggestions, err
+}
+
+func fetchSuggestions(client *http.Client, req *http.Request) ([]string, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("google: received non-200 status code: %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// The response has the form ["query", ["suggestion", ...], ...].
+	var payload []json.RawMessage
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("google: decoding suggestions: %w", err)
+	}
+	if len(payload) < 2 {
+		return nil, nil
+	}
+	var suggestions []string
+	if err := json.Unmarshal(payload[1], &suggestions); err != nil {
+		return nil, fmt.Errorf("google: decoding suggestions: %w", err)
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,54 @@
+package googlesearch
+
+import (
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SERPFeatures summarizes which SERP features Google showed for a query.
+// It is computed from cheap presence checks and is available even when
+// FeatureSummaryOnly disables parsing of the feature contents.
+type SERPFeatures struct {
+	FeaturedSnippet      bool
+	AnswerBox            bool
+	KnowledgePanel       bool
+	LocalPack            bool
+	VideoCarousel        bool
+	ImagePack            bool
+	TopStories           bool
+	PeopleAlsoAskCount   int
+	AdsCount             int
+	RelatedSearchesCount int
+}
+
+func (f *SERPFeatures) merge(other SERPFeatures) {
+	f.FeaturedSnippet = f.FeaturedSnippet || other.FeaturedSnippet
+	f.AnswerBox = f.AnswerBox || other.AnswerBox
+	f.KnowledgePanel = f.KnowledgePanel || other.KnowledgePanel
+	f.LocalPack = f.LocalPack || other.LocalPack
+	f.VideoCarousel = f.VideoCarousel || other.VideoCarousel
+	f.ImagePack = f.ImagePack || other.ImagePack
+	f.TopStories = f.TopStories || other.TopStories
+	f.PeopleAlsoAskCount += other.PeopleAlsoAskCount
+	f.AdsCount += other.AdsCount
+	if f.RelatedSearchesCount == 0 {
+		f.RelatedSearchesCount = other.RelatedSearchesCount
+	}
+}
+
+func extractFeatures(doc *goquery.Document) SERPFeatures {
+	has := func(selector string) bool {
+		return doc.Find(selector).Length() > 0
+	}
+	return SERPFeatures{
+		FeaturedSnippet:      has("div.xpdopen div.c2xzTb, block-component div.c2xzTb, div.V3FYCf"),
+		AnswerBox:            has("div#wob_wc, span.vLqKYe, div.rpnBye, div.lr_container, div.imso_mh__ma-sc-cont, div[data-attrid='kc:/finance:']"),
+		KnowledgePanel:       has("div.kp-wholepage, div.knowledge-panel, div#rhs div.kp-blk"),
+		LocalPack:            has("div.VkpGBb"),
+		VideoCarousel:        has("video-voyager, div.RzdJxc"),
+		ImagePack:            has("div#iur, div#imagebox_bigimages"),
+		TopStories:           has("g-section-with-header div.JJZKK, div.yG4QQe, g-scrolling-carousel div.WlydOe"),
+		PeopleAlsoAskCount:   doc.Find("div.related-question-pair").Length(),
+		AdsCount:             doc.Find("div#tads div[data-text-ad], div#bottomads div[data-text-ad], div#tadsb div[data-text-ad]").Length(),
+		RelatedSearchesCount: len(extractRelatedSearches(doc)),
+	}
+}
@@ -0,0 +1,188 @@
+package googlesearch
+
+import (
+	"context"
+	"sync"
+)
+
+// TeePolicy says what a Tee branch does when its consumer falls behind.
+type TeePolicy int
+
+const (
+	// TeeBlock waits for the consumer once its buffer is full, which holds
+	// up the source and with it every other branch.
+	TeeBlock TeePolicy = iota
+	// TeeQueue never holds up the source; responses queue without limit
+	// until the consumer catches up.
+	TeeQueue
+	// TeeDrop discards responses that do not fit in the buffer. Errors
+	// are never dropped.
+	TeeDrop
+)
+
+// TeeBranch configures one output of Tee.
+type TeeBranch struct {
+	Policy TeePolicy
+	// Buffer is the capacity of the output channel; TeeDrop branches get
+	// at least 1.
+	Buffer int
+	// OnDrop, if set, is called with every response a TeeDrop branch
+	// discards.
+	OnDrop func(SearchResponse)
+}
+
+// Tee copies every response of in, such as a SearchStream channel, to one
+// output channel per branch, so several consumers (an NDJSON writer, a
+// Store, application logic) each see the whole stream. Ranging over a
+// single channel from several goroutines would hand each response to only
+// one of them.
+//
+// The outputs are closed once in is closed and everything queued has been
+// delivered, or as soon as ctx is done. Every output must be drained, or
+// ctx cancelled, for a TeeBlock branch not to stall the others.
+func Tee(ctx context.Context, in <-chan SearchResponse, branches ...TeeBranch) []<-chan SearchResponse {
+	outputs := make([]*teeOutput, len(branches))
+	chans := make([]<-chan SearchResponse, len(branches))
+	for i, branch := range branches {
+		out := &teeOutput{TeeBranch: branch}
+		switch branch.Policy {
+		case TeeQueue:
+			out.queue = &teeQueue{ready: make(chan struct{}, 1)}
+			out.ch = make(chan SearchResponse, branch.Buffer)
+			go out.queue.drain(ctx, out.ch)
+		case TeeDrop:
+			out.ch = make(chan SearchResponse, max(branch.Buffer, 1))
+		default:
+			out.ch = make(chan SearchResponse, branch.Buffer)
+		}
+		outputs[i] = out
+		chans[i] = out.ch
+	}
+
+	go func() {
+		defer func() {
+			for _, out := range outputs {
+				out.close()
+			}
+		}()
+		for {
+			select {
+			case resp, ok := <-in:
+				if !ok {
+					return
+				}
+				for _, out := range outputs {
+					if !out.send(ctx, resp) {
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return chans
+}
+
+type teeOutput struct {
+	TeeBranch
+	ch    chan SearchResponse
+	queue *teeQueue
+}
+
+// send delivers resp according to the branch policy. It reports false
+// once ctx is done.
+func (o *teeOutput) send(ctx context.Context, resp SearchResponse) bool {
+	switch {
+	case o.queue != nil:
+		o.queue.push(resp)
+		return true
+	case o.Policy == TeeDrop && resp.Err == nil:
+		select {
+		case o.ch <- resp:
+		default:
+			if o.OnDrop != nil {
+				o.OnDrop(resp)
+			}
+		}
+		return true
+	}
+	select {
+	case o.ch <- resp:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// close closes the output channel, or for TeeQueue branches lets the
+// queue close it once drained.
+func (o *teeOutput) close() {
+	if o.queue != nil {
+		o.queue.close()
+		return
+	}
+	close(o.ch)
+}
+
+// teeQueue is the unbounded buffer of a TeeQueue branch.
+type teeQueue struct {
+	mu     sync.Mutex
+	items  []SearchResponse
+	closed bool
+	// ready is signalled after every push and on close.
+	ready chan struct{}
+}
+
+func (q *teeQueue) push(resp SearchResponse) {
+	q.mu.Lock()
+	q.items = append(q.items, resp)
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *teeQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *teeQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// drain moves queued responses to ch until the queue is closed and empty
+// or ctx is done, then closes ch.
+func (q *teeQueue) drain(ctx context.Context, ch chan<- SearchResponse) {
+	defer close(ch)
+	for {
+		q.mu.Lock()
+		if len(q.items) == 0 {
+			closed := q.closed
+			q.mu.Unlock()
+			if closed {
+				return
+			}
+			select {
+			case <-q.ready:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		resp := q.items[0]
+		q.items[0] = SearchResponse{}
+		q.items = q.items[1:]
+		q.mu.Unlock()
+
+		select {
+		case ch <- resp:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package googlesearch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TimeRange restricts results to pages indexed within a recent period,
+// like the "Any time" menu of Google's search tools.
+type TimeRange string
+
+const (
+	TimeRangeAny   TimeRange = ""
+	TimeRangeHour  TimeRange = "hour"
+	TimeRangeDay   TimeRange = "day"
+	TimeRangeWeek  TimeRange = "week"
+	TimeRangeMonth TimeRange = "month"
+	TimeRangeYear  TimeRange = "year"
+)
+
+// ParseTimeRange accepts the TimeRange names as well as "any" and the
+// single letter qdr codes (h, d, w, m, y).
+func ParseTimeRange(s string) (TimeRange, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "any":
+		return TimeRangeAny, nil
+	case "hour", "h":
+		return TimeRangeHour, nil
+	case "day", "d":
+		return TimeRangeDay, nil
+	case "week", "w":
+		return TimeRangeWeek, nil
+	case "month", "m":
+		return TimeRangeMonth, nil
+	case "year", "y":
+		return TimeRangeYear, nil
+	}
+	return TimeRangeAny, fmt.Errorf("google: unknown time range %q", s)
+}
+
+// tbs returns the tbs value for t, or "" for TimeRangeAny.
+func (t TimeRange) tbs() string {
+	if t == TimeRangeAny {
+		return ""
+	}
+	return "qdr:" + string(t[0])
+}
@@ -0,0 +1,68 @@
+package googlesearch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SearchTool adapts Search to the langchaingo tools.Tool interface (Name,
+// Description and Call) so it can be handed to an agent as its web search
+// tool. It satisfies the interface structurally, so this package does not
+// depend on langchaingo.
+type SearchTool struct {
+	// Options are used for every call; NumResults defaults to 5.
+	Options SearchOptions
+	// ToolName and ToolDescription override the defaults shown to the
+	// model.
+	ToolName        string
+	ToolDescription string
+}
+
+func (t SearchTool) Name() string {
+	if t.ToolName != "" {
+		return t.ToolName
+	}
+	return "google_search"
+}
+
+func (t SearchTool) Description() string {
+	if t.ToolDescription != "" {
+		return t.ToolDescription
+	}
+	return "Searches Google for current information. The input is a search query; the output lists the top results with title, URL and snippet."
+}
+
+// Call runs input as a search query and returns the results formatted with
+// FormatText.
+func (t SearchTool) Call(ctx context.Context, input string) (string, error) {
+	query := strings.TrimSpace(input)
+	if query == "" {
+		return "", fmt.Errorf("google: empty search query")
+	}
+	opts := t.Options
+	if opts.NumResults <= 0 {
+		opts.NumResults = 5
+	}
+	results, _, err := SearchWithMetadata(ctx, query, opts)
+	if err != nil {
+		return "", err
+	}
+	return FormatText(results), nil
+}
+
+// FormatText renders results as a numbered plain text list suited for LLM
+// prompts.
+func FormatText(results []SearchResult) string {
+	if len(results) == 0 {
+		return "No results found."
+	}
+	var b strings.Builder
+	for i, r := range results {
+		fmt.Fprintf(&b, "%d. %s\n   %s\n", i+1, r.Title, r.URL)
+		if r.Description != "" {
+			fmt.Fprintf(&b, "   %s\n", r.Description)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package googlesearch
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// extractTranslation fills the original-language fields of a result shown
+// in translated results mode, where Google renders the translated title in
+// the link and keeps the original title in an element tagged with its lang.
+func extractTranslation(result *SearchResult, s *goquery.Selection, base *url.URL) {
+	s.Find("[lang]").EachWithBreak(func(i int, el *goquery.Selection) bool {
+		text := normalizeSpace(el.Text())
+		if text == "" || text == normalizeSpace(result.Title) {
+			return true
+		}
+		result.OriginalTitle = text
+		result.OriginalLanguage = el.AttrOr("lang", "")
+		return false
+	})
+
+	s.Find("a[href]").EachWithBreak(func(i int, a *goquery.Selection) bool {
+		href := resolveGoogleURL(base, a.AttrOr("href", ""))
+		if strings.Contains(href, "translate.google.") {
+			result.TranslationURL = href
+			return false
+		}
+		return true
+	})
+}
@@ -0,0 +1,189 @@
+// Package trends fetches related and rising queries for a keyword from the
+// Google Trends endpoints.
+package trends
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"time"
+
+	"github.com/corpix/uarand"
+)
+
+const baseURL = "https://trends.google.com/trends"
+
+type Options struct {
+	Lang string
+	// Geo restricts the data to a country or region code such as "US" or
+	// "DE-BE". Empty means worldwide.
+	Geo string
+	// TimeRange uses the Trends syntax, e.g. "today 12-m" or "now 7-d".
+	TimeRange string
+	Category  int
+	Client    *http.Client
+}
+
+type Query struct {
+	Query string
+	// Value is the relative interest (0-100) for top queries and the
+	// percentage increase for rising ones.
+	Value          int
+	FormattedValue string
+	Link           string
+}
+
+type RelatedQueries struct {
+	Top    []Query
+	Rising []Query
+}
+
+type widget struct {
+	ID      string          `json:"id"`
+	Token   string          `json:"token"`
+	Request json.RawMessage `json:"request"`
+}
+
+// Related returns the top and rising queries related to keyword.
+func Related(ctx context.Context, keyword string, opts Options) (*RelatedQueries, error) {
+	if opts.Lang == "" {
+		opts.Lang = "en-US"
+	}
+	if opts.TimeRange == "" {
+		opts.TimeRange = "today 12-m"
+	}
+	client := opts.Client
+	if client == nil {
+		jar, _ := cookiejar.New(nil)
+		client = &http.Client{Timeout: 30 * time.Second, Jar: jar}
+	}
+	ua := uarand.GetRandom()
+
+	// Trends rejects requests without the NID cookie set on the landing page.
+	if _, err := get(ctx, client, ua, baseURL+"/?geo="+url.QueryEscape(opts.Geo)); err != nil {
+		return nil, err
+	}
+
+	exploreReq, err := json.Marshal(map[string]interface{}{
+		"comparisonItem": []map[string]string{{
+			"keyword": keyword,
+			"geo":     opts.Geo,
+			"time":    opts.TimeRange,
+		}},
+		"category": opts.Category,
+		"property": "",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Set("hl", opts.Lang)
+	q.Set("tz", "0")
+	q.Set("req", string(exploreReq))
+	body, err := get(ctx, client, ua, baseURL+"/api/explore?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var explore struct {
+		Widgets []widget `json:"widgets"`
+	}
+	if err := json.Unmarshal(stripPrefix(body), &explore); err != nil {
+		return nil, fmt.Errorf("trends: decoding explore response: %w", err)
+	}
+
+	var related *widget
+	for i := range explore.Widgets {
+		if explore.Widgets[i].ID == "RELATED_QUERIES" {
+			related = &explore.Widgets[i]
+			break
+		}
+	}
+	if related == nil {
+		return &RelatedQueries{}, nil
+	}
+
+	q = url.Values{}
+	q.Set("hl", opts.Lang)
+	q.Set("tz", "0")
+	q.Set("req", string(related.Request))
+	q.Set("token", related.Token)
+	body, err = get(ctx, client, ua, baseURL+"/api/widgetdata/relatedsearches?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		Default struct {
+			RankedList []struct {
+				RankedKeyword []struct {
+					Query          string `json:"query"`
+					Value          int    `json:"value"`
+					FormattedValue string `json:"formattedValue"`
+					Link           string `json:"link"`
+				} `json:"rankedKeyword"`
+			} `json:"rankedList"`
+		} `json:"default"`
+	}
+	if err := json.Unmarshal(stripPrefix(body), &data); err != nil {
+		return nil, fmt.Errorf("trends: decoding related queries: %w", err)
+	}
+
+	result := &RelatedQueries{}
+	for i, list := range data.Default.RankedList {
+		var queries []Query
+		for _, kw := range list.RankedKeyword {
+			link := kw.Link
+			if link != "" && link[0] == '/' {
+				link = "https://trends.google.com" + link
+			}
+			queries = append(queries, Query{
+				Query:          kw.Query,
+				Value:          kw.Value,
+				FormattedValue: kw.FormattedValue,
+				Link:           link,
+			})
+		}
+		switch i {
+		case 0:
+			result.Top = queries
+		case 1:
+			result.Rising = queries
+		}
+	}
+	return result, nil
+}
+
+func get(ctx context.Context, client *http.Client, ua, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", ua)
+	req.Header.Set("Accept", "application/json, text/plain, */*")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("trends: received non-200 status code: %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// stripPrefix removes the ")]}'" XSSI guard Trends prepends to JSON bodies.
+func stripPrefix(body []byte) []byte {
+	if i := bytes.IndexByte(body, '{'); i >= 0 {
+		return body[i:]
+	}
+	return body
+}
@@ -0,0 +1,67 @@
+package googlesearch
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const uuleKeys = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// EncodeUULE converts a Google Ads canonical location name such as
+// "Berlin,Berlin,Germany" into the value expected by the uule parameter.
+func EncodeUULE(canonicalName string) (string, error) {
+	if canonicalName == "" {
+		return "", fmt.Errorf("google: empty canonical location name")
+	}
+	if len(canonicalName) >= len(uuleKeys) {
+		return "", fmt.Errorf("google: canonical location name too long for uule: %d bytes", len(canonicalName))
+	}
+	return "w+CAIQICI" + string(uuleKeys[len(canonicalName)]) + base64.StdEncoding.EncodeToString([]byte(canonicalName)), nil
+}
+
+// IsUULE reports whether s already is an encoded uule value rather than a
+// canonical location name.
+func IsUULE(s string) bool {
+	return strings.HasPrefix(s, "w+") || strings.HasPrefix(s, "a+")
+}
+
+func resolveUULE(location string, coords *Coordinates) (string, error) {
+	if coords != nil {
+		if location != "" {
+			return "", fmt.Errorf("google: Location and Coordinates are mutually exclusive")
+		}
+		return EncodeUULECoordinates(*coords)
+	}
+	if location == "" || IsUULE(location) {
+		return location, nil
+	}
+	return EncodeUULE(location)
+}
+
+// Coordinates describes a device position used to simulate "near me"
+// queries. RadiusMeters defaults to 1000 when zero.
+type Coordinates struct {
+	Latitude     float64
+	Longitude    float64
+	RadiusMeters int
+}
+
+// EncodeUULECoordinates encodes a latitude/longitude pair into the "a+"
+// uule variant Google uses for device-reported locations.
+func EncodeUULECoordinates(c Coordinates) (string, error) {
+	if c.Latitude < -90 || c.Latitude > 90 || c.Longitude < -180 || c.Longitude > 180 {
+		return "", fmt.Errorf("google: invalid coordinates: %f,%f", c.Latitude, c.Longitude)
+	}
+	radius := c.RadiusMeters
+	if radius <= 0 {
+		radius = 1000
+	}
+
+	text := fmt.Sprintf("role: CURRENT_LOCATION\nproducer: DEVICE_LOCATION\nradius: %d\nlatlng {\n  latitude_e7: %d\n  longitude_e7: %d\n}\n",
+		radius,
+		int64(math.Round(c.Latitude*1e7)),
+		int64(math.Round(c.Longitude*1e7)))
+	return "a+" + base64.URLEncoding.EncodeToString([]byte(text)), nil
+}
@@ -0,0 +1,157 @@
+package googlesearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookSink POSTs streamed results as JSON batches to URL. When Secret is
+// set each request carries an X-Signature-256 header of the form
+// "sha256=<hex HMAC-SHA256 of the body>" so receivers can verify it.
+type WebhookSink struct {
+	URL    string
+	Secret []byte
+	// BatchSize is the number of results per request; it defaults to 10.
+	BatchSize int
+	// MaxRetries is how often a failed delivery is retried with
+	// exponential backoff; it defaults to 3.
+	MaxRetries int
+	Client     *http.Client
+}
+
+// WebhookPayload is the JSON body of a webhook request. Final is set on the
+// last batch of a search, which may be empty; Error carries the search
+// error, if any, and Labels those of the search, see WithLabels.
+type WebhookPayload struct {
+	Query   string          `json:"query"`
+	Batch   int             `json:"batch"`
+	Results []WebhookResult `json:"results"`
+	Final   bool            `json:"final"`
+	Error   string          `json:"error,omitempty"`
+	Labels  Labels          `json:"labels,omitempty"`
+}
+
+type WebhookResult struct {
+	Rank        int    `json:"rank"`
+	Page        int    `json:"page"`
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+func newWebhookResult(resp SearchResponse) WebhookResult {
+	return WebhookResult{
+		Rank:        resp.Rank,
+		Page:        resp.Page,
+		URL:         resp.Result.URL,
+		Title:       resp.Result.Title,
+		Description: resp.Result.Description,
+	}
+}
+
+// Consume delivers everything read from ch, typically the channel returned
+// by SearchStream for query, until it is closed. It returns the first
+// delivery error or the search error reported on the stream; after a
+// delivery error cancel the search's context so the stream is released.
+func (w *WebhookSink) Consume(ctx context.Context, query string, ch <-chan SearchResponse) error {
+	size := w.BatchSize
+	if size <= 0 {
+		size = 10
+	}
+
+	payload := WebhookPayload{Query: query, Batch: 1}
+	var searchErr error
+	for resp := range ch {
+		if resp.Labels != nil {
+			payload.Labels = resp.Labels
+		}
+		if resp.Err != nil {
+			searchErr = resp.Err
+			payload.Error = resp.Err.Error()
+			continue
+		}
+		payload.Results = append(payload.Results, newWebhookResult(resp))
+		if len(payload.Results) == size {
+			if err := w.Send(ctx, payload); err != nil {
+				return err
+			}
+			payload.Batch++
+			payload.Results = nil
+		}
+	}
+
+	payload.Final = true
+	if err := w.Send(ctx, payload); err != nil {
+		return err
+	}
+	return searchErr
+}
+
+// Send delivers a single payload, retrying on network errors and 5xx or
+// 429 responses.
+func (w *WebhookSink) Send(ctx context.Context, payload WebhookPayload) error {
+	// Receivers get an empty list rather than null.
+	if payload.Results == nil {
+		payload.Results = []WebhookResult{}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	retries := w.MaxRetries
+	if retries <= 0 {
+		retries = 3
+	}
+	backoff := 500 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, body)
+		if err == nil || !retry || attempt >= retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (w *WebhookSink) post(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.Secret) > 0 {
+		mac := hmac.New(sha256.New, w.Secret)
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("google: webhook %s returned status %d", w.URL, resp.StatusCode)
+}
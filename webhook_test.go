@@ -0,0 +1,45 @@
+package googlesearch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookSendEmptyResults(t *testing.T) {
+	bodies := make(chan []byte, 2)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer receiver.Close()
+	sink := &WebhookSink{URL: receiver.URL}
+	ctx := context.Background()
+
+	if err := sink.Send(ctx, WebhookPayload{Query: "golang", Final: true}); err != nil {
+		t.Fatal(err)
+	}
+	var empty map[string]json.RawMessage
+	if err := json.Unmarshal(<-bodies, &empty); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(empty["results"]); got != "[]" {
+		t.Errorf("empty batch sent results %s, want []", got)
+	}
+
+	// A result quoting the JSON of an empty batch is sent unchanged.
+	title := `"results":null`
+	if err := sink.Send(ctx, WebhookPayload{Query: "golang", Results: []WebhookResult{{Rank: 1, Title: title}}}); err != nil {
+		t.Fatal(err)
+	}
+	var payload WebhookPayload
+	if err := json.Unmarshal(<-bodies, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Results) != 1 || payload.Results[0].Title != title {
+		t.Errorf("sent results %+v, want the title %q kept", payload.Results, title)
+	}
+}